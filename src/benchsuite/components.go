@@ -11,6 +11,7 @@ import (
 
 	"regression-ci/internal/config"
 	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
 	"regression-ci/pkg/types"
 )
 
@@ -148,7 +149,7 @@ func setupDetector(db *sqlx.DB) *regression.Detector {
 		MinSamples:       5,
 		MaxSamples:       50,
 	}
-	return regression.New(db, cfg)
+	return regression.New(storage.New(db), cfg)
 }
 
 func createTestRequest() types.AnalyzeRequest {
@@ -0,0 +1,413 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"regression-ci/internal/client"
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/pkg/types"
+)
+
+var adminCommands []command
+
+func init() {
+	adminCommands = []command{
+		{"repos", "list repositories", adminRepos},
+		{"components", "list a repository's components and their baselines", adminComponents},
+		{"history", "show the recent samples of a component", adminHistory},
+		{"reset-baseline", "drop a component's baseline so the next run starts a new one", adminResetBaseline},
+		{"pin-baseline", "pin a component's baseline to a commit's samples", adminPinBaseline},
+		{"delete-run", "delete a run and rebuild the baselines it was part of", adminDeleteRun},
+		{"reanalyze", "analyse a commit's latest run again", adminReanalyze},
+		{"migrate", "apply pending database migrations (--db only)", adminMigrate},
+		{"vacuum", "reclaim space in the database", adminVacuum},
+	}
+}
+
+func runAdmin(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		adminUsage()
+		return exitOK
+	}
+
+	for _, cmd := range adminCommands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown admin command %q\n\n", args[0])
+	adminUsage()
+	return exitError
+}
+
+func adminUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: regression-ci admin <command> [flags] [args]\n\n"+
+		"Works on a database file with --db, or through a server's admin API.\n\nCommands:\n")
+	for _, cmd := range adminCommands {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun regression-ci admin <command> -h for the flags of a command.\n")
+}
+
+type adminOptions struct {
+	server    *string
+	token     string
+	db        string
+	artifacts string
+	json      bool
+}
+
+func adminFlags(name, args, summary string) (*flag.FlagSet, *adminOptions) {
+	fs := flag.NewFlagSet("admin "+name, flag.ContinueOnError)
+	opts := &adminOptions{server: serverFlag(fs)}
+	fs.StringVar(&opts.token, "token", "", "admin API token (default from "+config.EnvName("admin.token")+")")
+	fs.StringVar(&opts.db, "db", "", "work on this SQLite file or postgres:// URL instead of a server")
+	fs.StringVar(&opts.artifacts, "artifacts", "", "artifact directory with --db (default from the configuration)")
+	fs.BoolVar(&opts.json, "json", false, "print JSON instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: regression-ci admin %s [flags] %s\n\n%s\n\nFlags:\n", name, args, summary)
+		fs.PrintDefaults()
+	}
+	return fs, opts
+}
+
+// parseAdmin parses a command's flags and checks it got exactly n arguments.
+func parseAdmin(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != n {
+		fs.Usage()
+		return nil, fmt.Errorf("expected %d argument(s), got %d", n, len(positional))
+	}
+	return positional, nil
+}
+
+func (o *adminOptions) backend() (adminBackend, error) {
+	if o.db != "" {
+		return openLocal(o.db, o.artifacts)
+	}
+	token := o.token
+	if token == "" {
+		token = os.Getenv(config.EnvName("admin.token"))
+	}
+	return remoteAdmin{client: client.New(*o.server, token)}, nil
+}
+
+// withBackend runs fn against the selected backend and maps its error to an
+// exit code.
+func (o *adminOptions) withBackend(fn func(adminBackend) error) int {
+	backend, err := o.backend()
+	if err != nil {
+		return fail(err)
+	}
+	defer backend.Close()
+
+	if err := fn(backend); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+func adminRepos(args []string) int {
+	fs, opts := adminFlags("repos", "", "Lists the repositories with runs or history.")
+	if _, err := parseAdmin(fs, args, 0); err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		repos, err := b.Repos()
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			printJSON(os.Stdout, repos)
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REPO\tRUNS\tSERIES\tLAST RUN")
+		for _, repo := range repos {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", repo.Repo, repo.Runs, repo.Series, formatTime(repo.LastRunAt))
+		}
+		return tw.Flush()
+	})
+}
+
+func adminComponents(args []string) int {
+	fs, opts := adminFlags("components", "<repo>", "Lists every metric of every component of a repository.")
+	positional, err := parseAdmin(fs, args, 1)
+	if err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		series, err := b.Series(positional[0])
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			printJSON(os.Stdout, series)
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "COMPONENT\tMETRIC\tSAMPLES\tLAST SAMPLE\tBASELINE\tSOURCE")
+		for _, s := range series {
+			baseline := ""
+			if s.BaselineValue != nil {
+				baseline = fmt.Sprintf("%.4g", *s.BaselineValue)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", s.Component, s.Metric, s.Samples,
+				formatTime(s.LastTimestamp), baseline, s.BaselineSource)
+		}
+		return tw.Flush()
+	})
+}
+
+func adminHistory(args []string) int {
+	fs, opts := adminFlags("history", "<repo> <component>", "Shows a component's most recent samples, newest first.\n"+
+		"Downsampled history shows one median per bucket, without a run.")
+	metric := fs.String("metric", types.DefaultMetric, "metric of the component")
+	limit := fs.Int("limit", 50, "number of samples to show")
+	positional, err := parseAdmin(fs, args, 2)
+	if err != nil {
+		return fail(err)
+	}
+	if *limit <= 0 {
+		return fail(errors.New("--limit must be positive"))
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		history, err := b.History(positional[0], positional[1], *metric, *limit)
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			if history == nil {
+				history = []types.Benchmark{}
+			}
+			printJSON(os.Stdout, history)
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tCOMMIT\tBRANCH\tRUN\tVALUE")
+		for _, sample := range history {
+			run := "-"
+			if sample.RunID > 0 {
+				run = strconv.FormatInt(sample.RunID, 10)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.6g\n", formatTime(sample.Timestamp), shortCommit(sample.CommitHash),
+				sample.Branch, run, sample.Value)
+		}
+		return tw.Flush()
+	})
+}
+
+func adminResetBaseline(args []string) int {
+	fs, opts := adminFlags("reset-baseline", "<repo> <component>",
+		"Drops a component's baseline; the next run of it starts a new one.")
+	metric := fs.String("metric", types.DefaultMetric, "metric of the component")
+	positional, err := parseAdmin(fs, args, 2)
+	if err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		if err := b.ResetBaseline(positional[0], positional[1], *metric); err != nil {
+			return err
+		}
+		if opts.json {
+			printJSON(os.Stdout, map[string]string{"repo": positional[0], "component": positional[1], "metric": *metric})
+			return nil
+		}
+		fmt.Printf("reset the %s baseline of %s in %s\n", *metric, positional[1], positional[0])
+		return nil
+	})
+}
+
+func adminPinBaseline(args []string) int {
+	fs, opts := adminFlags("pin-baseline", "<repo> <component> <commit>",
+		"Pins a component's baseline to the samples measured at a commit.")
+	metric := fs.String("metric", types.DefaultMetric, "metric of the component")
+	positional, err := parseAdmin(fs, args, 3)
+	if err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		provenance, err := b.PinBaseline(positional[0], positional[1], *metric, positional[2])
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			printJSON(os.Stdout, provenance)
+			return nil
+		}
+		fmt.Printf("pinned the %s baseline of %s in %s to %s: %.4g from %d sample(s)\n", *metric, positional[1],
+			positional[0], shortCommit(positional[2]), provenance.Baseline.BaselineValue, provenance.Baseline.SampleCount)
+		return nil
+	})
+}
+
+func adminDeleteRun(args []string) int {
+	fs, opts := adminFlags("delete-run", "<run-id>",
+		"Deletes a run with its samples, verdicts and artifacts, and rebuilds the\n"+
+			"baselines it had samples in unless they are pinned.")
+	positional, err := parseAdmin(fs, args, 1)
+	if err != nil {
+		return fail(err)
+	}
+	id, err := strconv.ParseInt(positional[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fail(fmt.Errorf("invalid run id %q", positional[0]))
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		deleted, err := b.DeleteRun(id)
+		if deleted != nil {
+			printDeletedRun(os.Stdout, deleted, opts.json)
+		}
+		return err
+	})
+}
+
+func printDeletedRun(w io.Writer, deleted *types.DeletedRun, asJSON bool) {
+	if asJSON {
+		printJSON(w, deleted)
+		return
+	}
+
+	run := deleted.Run
+	fmt.Fprintf(w, "deleted run %d of %s at %s: %d sample(s), %d artifact(s)\n", run.ID, run.Repo,
+		shortCommit(run.CommitHash), deleted.Samples, len(deleted.Artifacts))
+	if len(deleted.Baselines) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tMETRIC\tREBUILT BASELINE\tSAMPLES")
+	for _, baseline := range deleted.Baselines {
+		fmt.Fprintf(tw, "%s\t%s\t%.4g\t%d\n", baseline.Component, baseline.Metric, baseline.BaselineValue,
+			baseline.SampleCount)
+	}
+	tw.Flush()
+}
+
+func adminReanalyze(args []string) int {
+	fs, opts := adminFlags("reanalyze", "<repo> <commit>",
+		"Analyses the latest run of a commit again against the current baselines,\n"+
+			"replacing its verdicts. Baselines are left untouched; components whose\n"+
+			"baseline was reset are reported as having none. Through a server this\n"+
+			"also updates the PR report.")
+	positional, err := parseAdmin(fs, args, 2)
+	if err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		resp, err := b.Reanalyze(positional[0], positional[1])
+		if err != nil {
+			return err
+		}
+		printAnalysis(os.Stdout, resp, opts.json)
+		return nil
+	})
+}
+
+func adminVacuum(args []string) int {
+	fs, opts := adminFlags("vacuum", "", "Reclaims the space of deleted rows.")
+	if _, err := parseAdmin(fs, args, 0); err != nil {
+		return fail(err)
+	}
+
+	return opts.withBackend(func(b adminBackend) error {
+		if err := b.Vacuum(); err != nil {
+			return err
+		}
+		if !opts.json {
+			fmt.Println("vacuumed database")
+		}
+		return nil
+	})
+}
+
+// adminMigrate only works on a database directly: servers migrate their
+// own database when they start.
+func adminMigrate(args []string) int {
+	fs, opts := adminFlags("migrate", "", "Applies pending migrations to the database given with --db.")
+	if _, err := parseAdmin(fs, args, 0); err != nil {
+		return fail(err)
+	}
+	if opts.db == "" {
+		return fail(errors.New("migrate needs --db; servers migrate their database when they start"))
+	}
+
+	db, err := database.Open(databaseConfig(opts.db))
+	if err != nil {
+		return fail(err)
+	}
+	defer db.Close()
+
+	applied, migrateErr := database.Migrate(db)
+	version, err := database.SchemaVersion(db)
+	if err != nil && migrateErr == nil {
+		migrateErr = err
+	}
+
+	if opts.json {
+		type migration struct {
+			Version int    `json:"version"`
+			Name    string `json:"name"`
+		}
+		result := struct {
+			Applied []migration `json:"applied"`
+			Version int         `json:"version"`
+		}{Applied: []migration{}, Version: version}
+		for _, m := range applied {
+			result.Applied = append(result.Applied, migration{m.Version, m.Name})
+		}
+		printJSON(os.Stdout, result)
+	} else {
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if migrateErr == nil {
+			fmt.Printf("database schema is at version %d\n", version)
+		}
+	}
+
+	if migrateErr != nil {
+		return fail(migrateErr)
+	}
+	return exitOK
+}
+
+func formatTime(unix int64) string {
+	if unix <= 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04:05")
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"regression-ci/internal/artifacts"
+	"regression-ci/internal/client"
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+// adminBackend is where admin commands are carried out: a database opened
+// directly, or a server's admin API.
+type adminBackend interface {
+	Repos() ([]types.RepoSummary, error)
+	Series(repo string) ([]types.SeriesSummary, error)
+	History(repo, component, metric string, limit int) ([]types.Benchmark, error)
+	ResetBaseline(repo, component, metric string) error
+	PinBaseline(repo, component, metric, commit string) (*types.BaselineProvenance, error)
+	Reanalyze(repo, commit string) (*types.AnalyzeResponse, error)
+	DeleteRun(id int64) (*types.DeletedRun, error)
+	Vacuum() error
+	Close() error
+}
+
+type remoteAdmin struct {
+	client *client.Client
+}
+
+func (r remoteAdmin) Repos() ([]types.RepoSummary, error) {
+	return r.client.Repos(context.Background())
+}
+
+func (r remoteAdmin) Series(repo string) ([]types.SeriesSummary, error) {
+	return r.client.Series(context.Background(), repo)
+}
+
+func (r remoteAdmin) History(repo, component, metric string, limit int) ([]types.Benchmark, error) {
+	return r.client.History(context.Background(), repo, component, metric, limit)
+}
+
+func (r remoteAdmin) ResetBaseline(repo, component, metric string) error {
+	return r.client.ResetBaseline(context.Background(), repo, component, metric)
+}
+
+func (r remoteAdmin) PinBaseline(repo, component, metric, commit string) (*types.BaselineProvenance, error) {
+	return r.client.PinBaseline(context.Background(), repo, component, metric, commit)
+}
+
+func (r remoteAdmin) Reanalyze(repo, commit string) (*types.AnalyzeResponse, error) {
+	return r.client.Reanalyze(context.Background(), repo, commit)
+}
+
+func (r remoteAdmin) DeleteRun(id int64) (*types.DeletedRun, error) {
+	return r.client.DeleteRun(context.Background(), id)
+}
+
+func (r remoteAdmin) Vacuum() error {
+	return r.client.Vacuum(context.Background())
+}
+
+func (r remoteAdmin) Close() error {
+	return nil
+}
+
+// localAdmin works on the database the way the server would, with the
+// detection settings from the configuration file and environment.
+type localAdmin struct {
+	store     storage.Store
+	detector  *regression.Detector
+	artifacts *artifacts.Store
+}
+
+// databaseConfig treats a postgres:// URL as a Postgres DSN and anything
+// else as the path of a SQLite file.
+func databaseConfig(db string) config.DatabaseConfig {
+	if strings.HasPrefix(db, "postgres://") || strings.HasPrefix(db, "postgresql://") {
+		return config.DatabaseConfig{Driver: database.DriverPostgres, DSN: db}
+	}
+	return config.DatabaseConfig{Driver: database.DriverSQLite, Path: db}
+}
+
+func openLocal(db, artifactsDir string) (*localAdmin, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if artifactsDir == "" {
+		artifactsDir = cfg.Artifacts.Dir
+	}
+
+	conn, err := database.Open(databaseConfig(db))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchema(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	store := storage.New(conn)
+	return &localAdmin{
+		store:     store,
+		detector:  regression.New(store, cfg.Detection),
+		artifacts: artifacts.New(artifactsDir),
+	}, nil
+}
+
+// checkSchema refuses databases that are not fully migrated rather than
+// migrating them as a side effect of another command.
+func checkSchema(db *sqlx.DB) error {
+	latest, err := database.LatestVersion()
+	if err != nil {
+		return err
+	}
+	version, err := database.SchemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("%w; run regression-ci admin migrate first", err)
+	}
+	if version < latest {
+		return fmt.Errorf("database schema is at version %d of %d; run regression-ci admin migrate first", version, latest)
+	}
+	if version > latest {
+		return fmt.Errorf("%w: database is at version %d, binary supports up to %d", database.ErrSchemaTooNew, version, latest)
+	}
+	return nil
+}
+
+func (l *localAdmin) Repos() ([]types.RepoSummary, error) {
+	return l.store.Repos()
+}
+
+func (l *localAdmin) Series(repo string) ([]types.SeriesSummary, error) {
+	return l.detector.Series(repo)
+}
+
+func (l *localAdmin) History(repo, component, metric string, limit int) ([]types.Benchmark, error) {
+	return l.store.RecentBenchmarks(repo, component, metric, limit)
+}
+
+func (l *localAdmin) ResetBaseline(repo, component, metric string) error {
+	return l.detector.ResetBaseline(repo, component, metric)
+}
+
+func (l *localAdmin) PinBaseline(repo, component, metric, commit string) (*types.BaselineProvenance, error) {
+	return l.detector.PinBaseline(repo, component, metric, commit)
+}
+
+func (l *localAdmin) Reanalyze(repo, commit string) (*types.AnalyzeResponse, error) {
+	return l.detector.Reanalyze(repo, commit)
+}
+
+func (l *localAdmin) DeleteRun(id int64) (*types.DeletedRun, error) {
+	deleted, err := l.detector.DeleteRun(id)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, l.artifacts.RemoveRun(id)
+}
+
+func (l *localAdmin) Vacuum() error {
+	return l.store.Vacuum()
+}
+
+func (l *localAdmin) Close() error {
+	return l.store.Close()
+}
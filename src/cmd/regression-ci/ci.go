@@ -0,0 +1,199 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"regression-ci/internal/client"
+)
+
+// detectRun fills whatever the flags left empty from the CI provider's
+// environment, then from the git checkout in the working directory.
+func detectRun(run *client.Run) error {
+	for _, provider := range []func() client.Run{githubActions, gitlabCI, circleCI, buildkite} {
+		merge(run, provider())
+	}
+	if run.Repo == "" || run.Branch == "" || run.Commit == "" {
+		merge(run, gitCheckout())
+	}
+
+	var missing []string
+	for _, field := range []struct{ flag, value string }{
+		{"--repo", run.Repo}, {"--branch", run.Branch}, {"--commit", run.Commit},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.flag)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("could not detect the run from the CI environment or git; pass %s",
+			strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func merge(run *client.Run, detected client.Run) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&run.Repo, detected.Repo)
+	fill(&run.Branch, detected.Branch)
+	fill(&run.Commit, detected.Commit)
+	fill(&run.CIURL, detected.CIURL)
+	fill(&run.Runner, detected.Runner)
+	if run.PRNumber == 0 {
+		run.PRNumber = detected.PRNumber
+	}
+}
+
+// githubActions reads the pull request's head commit from the event
+// payload: for pull_request events GITHUB_SHA is a merge commit that the
+// PR's checks are not reported on.
+func githubActions() client.Run {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return client.Run{}
+	}
+
+	run := client.Run{
+		Repo:   os.Getenv("GITHUB_REPOSITORY"),
+		Branch: os.Getenv("GITHUB_REF_NAME"),
+		Commit: os.Getenv("GITHUB_SHA"),
+		Runner: os.Getenv("RUNNER_NAME"),
+	}
+	if server, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID"); server != "" && id != "" {
+		run.CIURL = server + "/" + run.Repo + "/actions/runs/" + id
+	}
+
+	if head := os.Getenv("GITHUB_HEAD_REF"); head != "" {
+		run.Branch = head
+	}
+	if data, err := os.ReadFile(os.Getenv("GITHUB_EVENT_PATH")); err == nil {
+		var event struct {
+			PullRequest *struct {
+				Number int `json:"number"`
+				Head   struct {
+					SHA string `json:"sha"`
+				} `json:"head"`
+			} `json:"pull_request"`
+		}
+		if json.Unmarshal(data, &event) == nil && event.PullRequest != nil {
+			run.PRNumber = event.PullRequest.Number
+			run.Commit = event.PullRequest.Head.SHA
+		}
+	}
+	return run
+}
+
+func gitlabCI() client.Run {
+	if os.Getenv("GITLAB_CI") != "true" {
+		return client.Run{}
+	}
+
+	run := client.Run{
+		Repo:     os.Getenv("CI_PROJECT_PATH"),
+		Branch:   os.Getenv("CI_COMMIT_REF_NAME"),
+		Commit:   os.Getenv("CI_COMMIT_SHA"),
+		PRNumber: atoi(os.Getenv("CI_MERGE_REQUEST_IID")),
+		CIURL:    os.Getenv("CI_JOB_URL"),
+		Runner:   os.Getenv("CI_RUNNER_DESCRIPTION"),
+	}
+	if source := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"); source != "" {
+		run.Branch = source
+	}
+	return run
+}
+
+func circleCI() client.Run {
+	if os.Getenv("CIRCLECI") != "true" {
+		return client.Run{}
+	}
+
+	run := client.Run{
+		Branch:   os.Getenv("CIRCLE_BRANCH"),
+		Commit:   os.Getenv("CIRCLE_SHA1"),
+		PRNumber: atoi(os.Getenv("CIRCLE_PR_NUMBER")),
+		CIURL:    os.Getenv("CIRCLE_BUILD_URL"),
+	}
+	if owner, name := os.Getenv("CIRCLE_PROJECT_USERNAME"), os.Getenv("CIRCLE_PROJECT_REPONAME"); owner != "" && name != "" {
+		run.Repo = owner + "/" + name
+	}
+	if run.PRNumber == 0 {
+		run.PRNumber = atoi(path.Base(os.Getenv("CIRCLE_PULL_REQUEST")))
+	}
+	return run
+}
+
+func buildkite() client.Run {
+	if os.Getenv("BUILDKITE") != "true" {
+		return client.Run{}
+	}
+
+	return client.Run{
+		Repo:     repoFromRemote(os.Getenv("BUILDKITE_REPO")),
+		Branch:   os.Getenv("BUILDKITE_BRANCH"),
+		Commit:   os.Getenv("BUILDKITE_COMMIT"),
+		PRNumber: atoi(os.Getenv("BUILDKITE_PULL_REQUEST")),
+		CIURL:    os.Getenv("BUILDKITE_BUILD_URL"),
+		Runner:   os.Getenv("BUILDKITE_AGENT_NAME"),
+	}
+}
+
+func gitCheckout() client.Run {
+	run := client.Run{
+		Commit: git("rev-parse", "HEAD"),
+		Repo:   repoFromRemote(git("remote", "get-url", "origin")),
+	}
+	if branch := git("rev-parse", "--abbrev-ref", "HEAD"); branch != "HEAD" {
+		run.Branch = branch
+	}
+	return run
+}
+
+func git(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "regression-ci: git %s: %v\n", strings.Join(args, " "), err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// repoFromRemote turns a clone URL such as git@github.com:owner/name.git or
+// https://github.com/owner/name into owner/name.
+func repoFromRemote(remote string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), "/")
+	remote = strings.TrimSuffix(remote, ".git")
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+3:]
+	} else if i := strings.Index(remote, ":"); i >= 0 {
+		remote = "host/" + remote[i+1:]
+	}
+
+	parts := strings.Split(remote, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
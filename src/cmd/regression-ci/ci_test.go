@@ -0,0 +1,119 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"regression-ci/internal/client"
+)
+
+// clearCI unsets every provider marker, and the GitHub variables that
+// override others, so the host's own CI cannot leak in.
+func clearCI(t *testing.T) {
+	for _, key := range []string{"GITHUB_ACTIONS", "GITLAB_CI", "CIRCLECI", "BUILDKITE", "GITHUB_HEAD_REF", "GITHUB_EVENT_PATH"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestDetectRun(t *testing.T) {
+	event := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(event, []byte(`{"pull_request": {"number": 42, "head": {"sha": "headsha"}}}`), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want client.Run
+	}{
+		{"github push", map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_REPOSITORY": "octo/app", "GITHUB_REF_NAME": "main",
+			"GITHUB_SHA": "pushsha", "RUNNER_NAME": "runner-1",
+			"GITHUB_SERVER_URL": "https://github.com", "GITHUB_RUN_ID": "7",
+		}, client.Run{Repo: "octo/app", Branch: "main", Commit: "pushsha", Runner: "runner-1",
+			CIURL: "https://github.com/octo/app/actions/runs/7"}},
+		{"github pull request uses the head commit", map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_REPOSITORY": "octo/app", "GITHUB_REF_NAME": "42/merge",
+			"GITHUB_HEAD_REF": "feature", "GITHUB_SHA": "mergesha", "GITHUB_EVENT_PATH": event,
+		}, client.Run{Repo: "octo/app", Branch: "feature", Commit: "headsha", PRNumber: 42}},
+		{"gitlab merge request", map[string]string{
+			"GITLAB_CI": "true", "CI_PROJECT_PATH": "group/app", "CI_COMMIT_REF_NAME": "refs/merge",
+			"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature", "CI_COMMIT_SHA": "glsha",
+			"CI_MERGE_REQUEST_IID": "9", "CI_JOB_URL": "https://gitlab.com/job/1", "CI_RUNNER_DESCRIPTION": "shared",
+		}, client.Run{Repo: "group/app", Branch: "feature", Commit: "glsha", PRNumber: 9,
+			CIURL: "https://gitlab.com/job/1", Runner: "shared"}},
+		{"circleci pull request url", map[string]string{
+			"CIRCLECI": "true", "CIRCLE_PROJECT_USERNAME": "octo", "CIRCLE_PROJECT_REPONAME": "app",
+			"CIRCLE_BRANCH": "feature", "CIRCLE_SHA1": "circlesha",
+			"CIRCLE_PULL_REQUEST": "https://github.com/octo/app/pull/12", "CIRCLE_BUILD_URL": "https://circleci.com/b/1",
+		}, client.Run{Repo: "octo/app", Branch: "feature", Commit: "circlesha", PRNumber: 12,
+			CIURL: "https://circleci.com/b/1"}},
+		{"buildkite", map[string]string{
+			"BUILDKITE": "true", "BUILDKITE_REPO": "git@github.com:octo/app.git", "BUILDKITE_BRANCH": "main",
+			"BUILDKITE_COMMIT": "bksha", "BUILDKITE_PULL_REQUEST": "false",
+			"BUILDKITE_BUILD_URL": "https://buildkite.com/b/1", "BUILDKITE_AGENT_NAME": "agent",
+		}, client.Run{Repo: "octo/app", Branch: "main", Commit: "bksha",
+			CIURL: "https://buildkite.com/b/1", Runner: "agent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCI(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var run client.Run
+			if err := detectRun(&run); err != nil {
+				t.Fatalf("detect failed: %v", err)
+			}
+			if run != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, run)
+			}
+		})
+	}
+}
+
+func TestDetectRunKeepsFlags(t *testing.T) {
+	clearCI(t)
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_REPOSITORY", "octo/app")
+	t.Setenv("GITHUB_REF_NAME", "main")
+	t.Setenv("GITHUB_SHA", "envsha")
+
+	run := client.Run{Commit: "flagsha", PRNumber: 3}
+	if err := detectRun(&run); err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	if run.Commit != "flagsha" || run.PRNumber != 3 || run.Repo != "octo/app" {
+		t.Fatalf("expected flags to win over the environment, got %+v", run)
+	}
+}
+
+func TestRepoFromRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:octo/app.git", "octo/app"},
+		{"git@github.com:octo/app", "octo/app"},
+		{"https://github.com/octo/app", "octo/app"},
+		{"https://github.com/octo/app.git", "octo/app"},
+		{"https://github.com/octo/app/", "octo/app"},
+		{"https://token@github.com/octo/app.git", "octo/app"},
+		{"ssh://git@github.com:22/octo/app.git", "octo/app"},
+		{"  git@github.com:octo/app.git\n", "octo/app"},
+		{"github.com:app", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := repoFromRemote(tt.remote); got != tt.want {
+			t.Errorf("repoFromRemote(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
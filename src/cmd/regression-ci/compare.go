@@ -0,0 +1,213 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+type compareReport struct {
+	Old         string                  `json:"old"`
+	New         string                  `json:"new"`
+	Regressions int                     `json:"regressions"`
+	Comparisons []regression.Comparison `json:"comparisons"`
+}
+
+func runCompare(args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	detection := config.DefaultDetection()
+	format := flags.String("format", "", "benchmark output format of both files (default detected)")
+	flags.Float64Var(&detection.DefaultThreshold, "threshold", detection.DefaultThreshold, "percent change that is a regression")
+	flags.Float64Var(&detection.AbsoluteThreshold, "absolute-threshold", detection.AbsoluteThreshold, "change that is a regression for metrics near zero")
+	flags.Float64Var(&detection.SignificanceLevel, "alpha", detection.SignificanceLevel, "p-value below which a change is significant, 0 to skip the test")
+	repoFile := flags.String("config", config.RepoFileName, "repository config with per-component thresholds, read when present")
+	markdown := flags.Bool("markdown", false, "print a Markdown table")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: regression-ci compare [flags] <old> <new>\n\n"+
+			"Compares two benchmark result files locally with the server's statistics\n"+
+			"and exits with status %d when new has regressions.\n\nFlags:\n", exitRegression)
+		flags.PrintDefaults()
+	}
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return fail(err)
+	}
+	if len(positional) != 2 {
+		flags.Usage()
+		return exitError
+	}
+	if *markdown && *asJSON {
+		return fail(errors.New("--markdown and --json are exclusive"))
+	}
+	if detection.DefaultThreshold <= 0 || detection.AbsoluteThreshold <= 0 {
+		return fail(errors.New("--threshold and --absolute-threshold must be positive"))
+	}
+	if detection.SignificanceLevel < 0 || detection.SignificanceLevel >= 1 {
+		return fail(errors.New("--alpha must be in [0, 1)"))
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	file, err := readRepoFile(*repoFile, set["config"])
+	if err != nil {
+		return fail(err)
+	}
+	// Thresholds given on the command line win over the file's defaults,
+	// though not over its per-component ones.
+	if file != nil && set["threshold"] {
+		file.ThresholdPercent = nil
+	}
+	if file != nil && set["absolute-threshold"] {
+		file.AbsoluteThreshold = nil
+	}
+
+	var results [2][]types.Measurement
+	for i, path := range positional {
+		result, _, err := parseResults(path, *format)
+		if err != nil {
+			return fail(fmt.Errorf("%s: %w", path, err))
+		}
+		results[i] = result.Measurements
+	}
+
+	report := compareReport{
+		Old:         positional[0],
+		New:         positional[1],
+		Comparisons: regression.Compare(detection, file, results[0], results[1]),
+	}
+	for _, comparison := range report.Comparisons {
+		if comparison.Verdict == regression.VerdictRegression {
+			report.Regressions++
+		}
+	}
+
+	switch {
+	case *asJSON:
+		printJSON(os.Stdout, report)
+	case *markdown:
+		printCompareMarkdown(os.Stdout, report)
+	default:
+		printCompare(os.Stdout, report)
+	}
+
+	if report.Regressions > 0 {
+		fmt.Fprintf(os.Stderr, "regression-ci: %d performance regression(s) in %s\n", report.Regressions, report.New)
+		return exitRegression
+	}
+	return exitOK
+}
+
+// readRepoFile reads the repository config, which is optional unless it was
+// named explicitly.
+func readRepoFile(path string, required bool) (*config.RepoFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file, problems := config.ParseRepoFile(data)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(problems, "; "))
+	}
+	return file, nil
+}
+
+func printCompare(w io.Writer, report compareReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tMETRIC\tOLD\tNEW\tDELTA\tP-VALUE\tVERDICT")
+	for _, c := range report.Comparisons {
+		old, current, delta, pValue := compareColumns(c)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Component, c.Metric, old, current, delta, pValue,
+			compareVerdict(c.Verdict))
+	}
+	tw.Flush()
+}
+
+func printCompareMarkdown(w io.Writer, report compareReport) {
+	fmt.Fprintf(w, "## Performance comparison\n\n`%s` vs `%s`\n\n", report.Old, report.New)
+	if report.Regressions > 0 {
+		fmt.Fprintf(w, "**%d performance regression(s)**\n\n", report.Regressions)
+	} else {
+		fmt.Fprintf(w, "**No performance regressions**\n\n")
+	}
+
+	fmt.Fprintln(w, "| Component | Old | New | Change | p-value | Verdict |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---|")
+	for _, c := range report.Comparisons {
+		name := "`" + c.Component + "`"
+		if c.Metric != types.DefaultMetric {
+			name += " " + c.Metric
+		}
+		old, current, delta, pValue := compareColumns(c)
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n", name, old, current, delta, pValue,
+			markdownVerdict(c.Verdict))
+	}
+}
+
+// compareColumns formats a comparison the way benchstat does, with the
+// sample counts next to the p-value.
+func compareColumns(c regression.Comparison) (old, current, delta, pValue string) {
+	unit := ""
+	if c.Unit != "" {
+		unit = " " + c.Unit
+	}
+
+	result := c.Result
+	if result == nil {
+		if c.OldSamples > 0 {
+			return fmt.Sprintf("n=%d", c.OldSamples), "", "", ""
+		}
+		return "", fmt.Sprintf("n=%d", c.NewSamples), "", ""
+	}
+
+	delta = fmt.Sprintf("%+.2f%%", result.PercentChange)
+	if result.ThresholdMode == regression.ThresholdModeAbsolute {
+		delta = fmt.Sprintf("%+.4g", result.AbsoluteChange)
+	}
+	pValue = fmt.Sprintf("n=%d+%d", c.OldSamples, c.NewSamples)
+	if result.PValue != nil {
+		pValue = fmt.Sprintf("%.3f %s", *result.PValue, pValue)
+	}
+	return fmt.Sprintf("%.4g%s", result.BaselineValue, unit), fmt.Sprintf("%.4g%s", result.CurrentValue, unit), delta, pValue
+}
+
+func compareVerdict(verdict string) string {
+	switch verdict {
+	case regression.VerdictRegression:
+		return "REGRESSION"
+	case regression.VerdictUnchanged:
+		return "~"
+	default:
+		return verdict
+	}
+}
+
+func markdownVerdict(verdict string) string {
+	switch verdict {
+	case regression.VerdictRegression:
+		return ":x: regression"
+	case regression.VerdictImprovement:
+		return ":rocket: improvement"
+	case regression.VerdictUnchanged:
+		return ":white_check_mark: ok"
+	default:
+		return verdict
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	exitOK         = 0
+	exitRegression = 1
+	exitError      = 2
+
+	defaultServer = "http://localhost:8080"
+	serverEnv     = "REGRESSION_CI_SERVER_URL"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"upload", "upload benchmark output and print the analysis", runUpload},
+		{"analyze", "upload or wait for an analysis and fail on regressions", runAnalyze},
+		{"compare", "compare two result files locally and fail on regressions", runCompare},
+		{"admin", "inspect and repair the data of a server or database", runAdmin},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(exitOK)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(exitError)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: regression-ci <command> [flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun regression-ci <command> -h for the flags of a command.\n")
+}
+
+// parseArgs lets flags follow positional arguments, as CI scripts tend to
+// write them.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// fail reports an error and returns the exit code for it; -h is not one.
+func fail(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	fmt.Fprintf(os.Stderr, "regression-ci: %v\n", err)
+	return exitError
+}
+
+func serverFlag(fs *flag.FlagSet) *string {
+	server := os.Getenv(serverEnv)
+	if server == "" {
+		server = defaultServer
+	}
+	return fs.String("server", server, "server URL (default from "+serverEnv+")")
+}
+
+// stringList is a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+
+	"regression-ci/internal/client"
+	"regression-ci/internal/formats"
+	"regression-ci/pkg/types"
+)
+
+func printJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func printAnalysis(w io.Writer, resp *types.AnalyzeResponse, asJSON bool) {
+	if asJSON {
+		printJSON(w, resp)
+		return
+	}
+
+	fmt.Fprintf(w, "run %d of %s at %s\n\n", resp.RunID, resp.Repo, resp.Commit)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tMETRIC\tBASELINE\tCURRENT\tCHANGE\tP-VALUE\tVERDICT")
+	for _, component := range resp.Components {
+		result := component.Result
+		if result == nil {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\t\terror: %s\n", component.Component, component.Metric, component.Error)
+			continue
+		}
+
+		change := fmt.Sprintf("%+.2f%%", result.PercentChange)
+		if result.ThresholdMode == "absolute" {
+			change = fmt.Sprintf("%+.4g", result.AbsoluteChange)
+		}
+		pValue := ""
+		if result.PValue != nil {
+			pValue = fmt.Sprintf("%.3f", *result.PValue)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.4g\t%.4g\t%s\t%s\t%s\n", component.Component, component.Metric,
+			result.BaselineValue, result.CurrentValue, change, pValue, verdict(resp, component))
+	}
+	tw.Flush()
+}
+
+func verdict(resp *types.AnalyzeResponse, component types.ComponentResult) string {
+	switch {
+	case !component.Result.IsRegression:
+		return "ok"
+	case component.Acknowledgement != nil:
+		return "accepted by " + component.Acknowledgement.Author
+	case resp.Policy != nil && resp.Policy.Accepted:
+		return "accepted"
+	case resp.Policy != nil && resp.Policy.Ignore:
+		return "regression (ignored)"
+	default:
+		return "REGRESSION"
+	}
+}
+
+// printDryRun shows the request an upload would make and, in place of the
+// body, what the server will read from it.
+func printDryRun(w io.Writer, c *client.Client, source string, upload client.Upload, result *formats.Result, asJSON bool) {
+	if asJSON {
+		printJSON(w, types.AnalyzeRequest{
+			Repo:         upload.Run.Repo,
+			Branch:       upload.Run.Branch,
+			Commit:       upload.Run.Commit,
+			PRNumber:     upload.Run.PRNumber,
+			Measurements: result.Measurements,
+			Metadata:     result.Metadata,
+			CIURL:        upload.Run.CIURL,
+			Runner:       upload.Run.Runner,
+		})
+		return
+	}
+
+	fmt.Fprintf(w, "POST %s\n", c.IngestURL(upload))
+	if upload.Multipart() {
+		fmt.Fprintln(w, "Content-Type: multipart/form-data")
+	} else {
+		fmt.Fprintf(w, "Content-Type: %s\n", upload.ContentType)
+	}
+	if upload.Compress {
+		fmt.Fprintln(w, "Content-Encoding: gzip")
+	}
+
+	fmt.Fprintf(w, "\nresults: %s (%s)\n", source, upload.Format)
+	for _, artifact := range upload.Artifacts {
+		fmt.Fprintf(w, "artifact: %s\n", filepath.Base(artifact))
+	}
+	for _, profile := range upload.Profiles {
+		fmt.Fprintf(w, "profile[%s]: %s\n", profile.Component, filepath.Base(profile.Path))
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tMETRIC\tUNIT\tSAMPLES\tMEAN\tMIN\tMAX")
+	for _, m := range result.Measurements {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.4g\t%.4g\t%.4g\n", m.Component, m.Metric, m.Unit, len(m.Samples),
+			stat.Mean(m.Samples, nil), floats.Min(m.Samples), floats.Max(m.Samples))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,339 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"regression-ci/internal/client"
+	"regression-ci/internal/formats"
+	"regression-ci/internal/profiles"
+	"regression-ci/internal/report"
+	"regression-ci/pkg/types"
+)
+
+type uploadOptions struct {
+	server    *string
+	run       client.Run
+	format    string
+	artifacts stringList
+	profiles  stringList
+	noGzip    bool
+	dryRun    bool
+	json      bool
+}
+
+func uploadFlags(fs *flag.FlagSet) *uploadOptions {
+	opts := &uploadOptions{server: serverFlag(fs)}
+	fs.StringVar(&opts.run.Repo, "repo", "", "repository as owner/name (default from CI or git)")
+	fs.StringVar(&opts.run.Branch, "branch", "", "branch the results were measured on (default from CI or git)")
+	fs.StringVar(&opts.run.Commit, "commit", "", "commit the results were measured at (default from CI or git)")
+	fs.IntVar(&opts.run.PRNumber, "pr", 0, "pull request number (default from CI)")
+	fs.StringVar(&opts.run.CIURL, "ci-url", "", "link to the CI job (default from CI)")
+	fs.StringVar(&opts.run.Runner, "runner", "", "name of the machine the benchmarks ran on (default from CI)")
+	fs.Int64Var(&opts.run.BisectionID, "bisection", 0,
+		"bisection the run measures a midpoint for, from the dispatch's client_payload.bisection_id")
+	fs.StringVar(&opts.format, "format", "", "benchmark output format (default detected)")
+	fs.Var(&opts.artifacts, "artifact", "file to attach to the run, such as a log (repeatable)")
+	fs.Var(&opts.profiles, "profile", "pprof profile of a component as component=file (repeatable)")
+	fs.BoolVar(&opts.noGzip, "no-gzip", false, "send the body uncompressed")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print what would be sent instead of sending it")
+	fs.BoolVar(&opts.json, "json", false, "print JSON instead of a table")
+	return opts
+}
+
+func runUpload(args []string) int {
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	opts := uploadFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: regression-ci upload [flags] <results>\n\n"+
+			"Uploads benchmark output, a file or a Criterion.rs target/criterion directory,\n"+
+			"and prints the analysis.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return fail(err)
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	resp, err := opts.upload(positional[0])
+	if err != nil {
+		return fail(err)
+	}
+	if resp != nil {
+		printAnalysis(os.Stdout, resp, opts.json)
+	}
+	return exitOK
+}
+
+func runAnalyze(args []string) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	opts := uploadFlags(fs)
+	wait := fs.Bool("wait", false, "without results, wait for another job's upload of the commit to be analyzed")
+	timeout := fs.Duration("timeout", 10*time.Minute, "how long --wait waits")
+	interval := fs.Duration("interval", 10*time.Second, "how often --wait polls")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: regression-ci analyze [flags] [<results>]\n\n"+
+			"Uploads results, or reads the commit's latest analysis when none are given,\n"+
+			"and exits with status %d when it has regressions that are not accepted.\n\nFlags:\n", exitRegression)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return fail(err)
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	var resp *types.AnalyzeResponse
+	if len(positional) == 1 {
+		resp, err = opts.upload(positional[0])
+	} else {
+		resp, err = opts.awaitAnalysis(*wait, *timeout, *interval)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if resp == nil {
+		return exitOK
+	}
+
+	printAnalysis(os.Stdout, resp, opts.json)
+	if open, _ := report.Regressions(resp); open > 0 && (resp.Policy == nil || !resp.Policy.Ignore) {
+		fmt.Fprintf(os.Stderr, "regression-ci: %d performance regression(s) in %s\n", open, resp.Commit)
+		return exitRegression
+	}
+	return exitOK
+}
+
+// upload sends results, or prints them with --dry-run, in which case there
+// is no analysis to return. A directory is sent as a gzipped tar.
+func (o *uploadOptions) upload(path string) (*types.AnalyzeResponse, error) {
+	if err := detectRun(&o.run); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	results, compress := path, !o.noGzip
+	if info.IsDir() {
+		if results, err = archiveDir(path); err != nil {
+			return nil, err
+		}
+		defer os.Remove(results)
+		compress = false
+	}
+
+	upload, result, err := o.prepare(results)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	upload.Compress = compress
+	if info.IsDir() {
+		upload.ContentType = "application/gzip"
+	}
+
+	c := client.New(*o.server, "")
+	if o.dryRun {
+		printDryRun(os.Stdout, c, path, upload, result, o.json)
+		return nil, nil
+	}
+	return c.Ingest(context.Background(), upload)
+}
+
+// prepare checks the upload locally: the results must be in a known format
+// and profiles must be pprof files for components in the results.
+func (o *uploadOptions) prepare(results string) (client.Upload, *formats.Result, error) {
+	upload := client.Upload{Run: o.run, Results: results}
+
+	result, name, err := parseResults(results, o.format)
+	if err != nil {
+		return upload, nil, err
+	}
+	format, _ := formats.Lookup(name)
+	upload.Format = name
+	upload.ContentType = format.ContentTypes[0]
+
+	for _, artifact := range o.artifacts {
+		if _, err := os.Stat(artifact); err != nil {
+			return upload, nil, err
+		}
+		upload.Artifacts = append(upload.Artifacts, artifact)
+	}
+
+	measured := make(map[string]bool)
+	for _, m := range result.Measurements {
+		measured[m.Component] = true
+	}
+	for _, spec := range o.profiles {
+		profile, err := parseProfileFlag(spec, measured)
+		if err != nil {
+			return upload, nil, err
+		}
+		upload.Profiles = append(upload.Profiles, profile)
+	}
+
+	return upload, result, nil
+}
+
+func parseResults(path, name string) (*formats.Result, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if name == "" {
+		detected, replay, err := formats.Detect("", f)
+		if err != nil {
+			return nil, "", err
+		}
+		defer replay.Close()
+		name, r = detected, replay
+	}
+
+	result, err := formats.Parse(name, "", r)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, name, nil
+}
+
+// parseProfileFlag splits component=file at the last "=", since component
+// names such as JMH's bench[size=1024] may contain one.
+func parseProfileFlag(spec string, measured map[string]bool) (client.Profile, error) {
+	i := strings.LastIndex(spec, "=")
+	if i <= 0 || i == len(spec)-1 {
+		return client.Profile{}, fmt.Errorf("--profile %q is not component=file", spec)
+	}
+	profile := client.Profile{Component: spec[:i], Path: spec[i+1:]}
+
+	if !measured[profile.Component] {
+		return profile, fmt.Errorf("--profile %q: %q is not a component in the results", spec, profile.Component)
+	}
+	data, err := os.ReadFile(profile.Path)
+	if err != nil {
+		return profile, err
+	}
+	if _, err := profiles.Parse(data); err != nil {
+		return profile, fmt.Errorf("%s: %w", profile.Path, err)
+	}
+	return profile, nil
+}
+
+// archiveDir writes a directory into a temporary gzipped tar, the form the
+// server reads Criterion.rs output in.
+func archiveDir(dir string) (string, error) {
+	file, err := os.CreateTemp("", "regression-ci-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	archive := tar.NewWriter(gz)
+	root := filepath.Base(filepath.Clean(dir))
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name:     filepath.ToSlash(filepath.Join(root, rel)),
+			Mode:     0o644,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			Typeflag: tar.TypeReg,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		return copyInto(archive, path)
+	})
+	if err == nil {
+		err = archive.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+	return file.Name(), nil
+}
+
+func copyInto(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// awaitAnalysis reads the commit's latest analysis, polling until it
+// exists when wait is set.
+func (o *uploadOptions) awaitAnalysis(wait bool, timeout, interval time.Duration) (*types.AnalyzeResponse, error) {
+	if err := detectRun(&o.run); err != nil {
+		return nil, err
+	}
+
+	c := client.New(*o.server, "")
+	if o.dryRun {
+		fmt.Printf("GET %s\n", c.CommitAnalysisURL(o.run.Repo, o.run.Commit))
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for {
+		resp, err := c.CommitAnalysis(ctx, o.run.Repo, o.run.Commit)
+		if !errors.Is(err, client.ErrNotFound) {
+			return resp, err
+		}
+		if !wait {
+			return nil, fmt.Errorf("%s has not been analyzed yet; pass --wait to wait for it", o.run.Commit)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s was not analyzed within %s", o.run.Commit, timeout)
+		case <-time.After(interval):
+		}
+	}
+}
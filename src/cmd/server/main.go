@@ -5,6 +5,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,26 +15,65 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"regression-ci/internal/backup"
 	"regression-ci/internal/config"
 	"regression-ci/internal/database"
 	"regression-ci/internal/server"
+	"regression-ci/internal/storage"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending database migrations and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s restore <backup-file>\n\nFlags:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	setupLogger()
 
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load configuration")
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+	if err := config.ApplyLogLevel(cfg.Logging.Level); err != nil {
+		log.Fatal().Err(err).Msg("invalid log level")
+	}
+
+	if flag.Arg(0) == "restore" {
+		restore(cfg, flag.Args()[1:])
+		return
+	}
 
-	db, err := database.Init(cfg.Database.Path)
+	db, err := database.Init(cfg.Database)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize database")
 	}
-	defer db.Close()
+	if *migrateOnly {
+		version, err := database.SchemaVersion(db)
+		db.Close()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to read schema version")
+		}
+		log.Info().Int("version", version).Msg("database migrated")
+		return
+	}
+	store := storage.New(db)
+	defer store.Close()
 
-	srv := server.New(db, cfg)
+	srv := server.New(store, cfg)
+
+	watching := config.Watch(func(updated *config.Config) {
+		if _, err := srv.ApplyConfig(updated); err != nil {
+			log.Error().Err(err).Msg("rejected configuration reload, keeping previous configuration")
+		}
+	})
+	if watching {
+		log.Info().Msg("watching configuration file for changes")
+	}
 
 	go func() {
 		log.Info().Str("address", cfg.Server.Address).Msg("starting server")
@@ -56,6 +97,26 @@ func main() {
 	log.Info().Msg("server shutdown complete")
 }
 
+// restore swaps a backup in for the configured SQLite database. It must run
+// while the server is stopped.
+func restore(cfg *config.Config, args []string) {
+	if len(args) != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if cfg.Database.Driver != database.DriverSQLite {
+		log.Fatal().Str("driver", cfg.Database.Driver).Msg("restore only supports the sqlite driver")
+	}
+
+	previous, err := backup.Restore(args[0], cfg.Database.Path)
+	if err != nil {
+		log.Fatal().Err(err).Str("backup", args[0]).Msg("restore failed")
+	}
+
+	log.Info().Str("backup", args[0]).Str("database", cfg.Database.Path).
+		Str("previous", previous).Msg("database restored")
+}
+
 func setupLogger() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	if os.Getenv("ENVIRONMENT") == "development" {
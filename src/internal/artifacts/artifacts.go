@@ -0,0 +1,163 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"regression-ci/pkg/types"
+)
+
+var (
+	ErrInvalidName   = errors.New("invalid artifact name")
+	ErrDuplicateName = errors.New("duplicate artifact name")
+)
+
+// Store keeps artifact content on disk, one directory per run. Uploads are
+// staged first because the run they belong to only exists once the results
+// have been analyzed.
+type Store struct {
+	dir string
+}
+
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) runDir(runID int64) string {
+	return filepath.Join(s.dir, "runs", strconv.FormatInt(runID, 10))
+}
+
+// Open returns the content of an artifact kept for a run.
+func (s *Store) Open(runID int64, name string) (*os.File, error) {
+	name, err := CleanName(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(s.runDir(runID), name))
+}
+
+// RemoveRun deletes the files of every artifact kept for a run.
+func (s *Store) RemoveRun(runID int64) error {
+	if err := os.RemoveAll(s.runDir(runID)); err != nil {
+		return fmt.Errorf("failed to remove run artifacts: %w", err)
+	}
+	return nil
+}
+
+// CleanName reduces an uploaded file name to its last element, so an
+// artifact can never be written outside its run's directory.
+func CleanName(name string) (string, error) {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return name, nil
+}
+
+type staged struct {
+	artifact types.Artifact
+	path     string
+}
+
+// Batch collects the artifacts of a single upload until they are kept for
+// a run or discarded.
+type Batch struct {
+	store *Store
+	files []staged
+	names map[string]bool
+}
+
+func (s *Store) NewBatch() *Batch {
+	return &Batch{store: s, names: make(map[string]bool)}
+}
+
+func (b *Batch) Len() int {
+	return len(b.files)
+}
+
+// Add copies r into the staging directory, hashing it on the way. The
+// artifact carries the name and descriptive fields; the rest is filled in.
+func (b *Batch) Add(artifact types.Artifact, r io.Reader) error {
+	name, err := CleanName(artifact.Name)
+	if err != nil {
+		return err
+	}
+	if b.names[name] {
+		return fmt.Errorf("%w: %q", ErrDuplicateName, name)
+	}
+
+	dir := filepath.Join(b.store.dir, "staging")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	file, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage artifact: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), r)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to stage artifact %s: %w", name, err)
+	}
+
+	artifact.Name = name
+	artifact.Size = size
+	artifact.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	b.names[name] = true
+	b.files = append(b.files, staged{artifact: artifact, path: file.Name()})
+	return nil
+}
+
+// Keep moves the staged files into the run's directory and returns their
+// metadata for the database.
+func (b *Batch) Keep(repo string, runID int64) ([]types.Artifact, error) {
+	dir := b.store.runDir(runID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	now := time.Now().Unix()
+	kept := make([]types.Artifact, 0, len(b.files))
+	for len(b.files) > 0 {
+		file := b.files[0]
+		if err := os.Rename(file.path, filepath.Join(dir, file.artifact.Name)); err != nil {
+			return nil, fmt.Errorf("failed to keep artifact %s: %w", file.artifact.Name, err)
+		}
+		b.files = b.files[1:]
+
+		artifact := file.artifact
+		artifact.RunID = runID
+		artifact.Repo = repo
+		artifact.CreatedAt = now
+		kept = append(kept, artifact)
+	}
+
+	return kept, nil
+}
+
+// Discard removes whatever is still staged.
+func (b *Batch) Discard() {
+	for _, file := range b.files {
+		os.Remove(file.path)
+	}
+	b.files = nil
+}
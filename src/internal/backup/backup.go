@@ -0,0 +1,223 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/storage"
+)
+
+const (
+	filePrefix = "regression-"
+	fileSuffix = ".db"
+	timeFormat = "20060102T150405Z"
+	// Backup names carry nanoseconds so two backups in the same second get
+	// distinct files; the fixed width keeps them sorting chronologically.
+	nameFormat = "20060102T150405.000000000Z"
+)
+
+type Info struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Create writes a timestamped backup into dir and then prunes all but the
+// newest keep backups. A keep of zero disables pruning.
+func Create(store storage.MaintenanceStore, dir string, keep int) (*Info, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path, now, err := reserve(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Backup(path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	if keep > 0 {
+		if err := Rotate(dir, keep); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Info{Path: path, Size: stat.Size(), CreatedAt: now.Unix()}, nil
+}
+
+// reserve creates an empty file under a fresh name so that no other backup
+// can claim it. VACUUM INTO accepts an existing empty file, and a failed
+// backup only ever removes the file reserved here.
+func reserve(dir string) (string, time.Time, error) {
+	for attempt := 0; attempt < 10; attempt++ {
+		now := time.Now().UTC()
+		path := filepath.Join(dir, filePrefix+now.Format(nameFormat)+fileSuffix)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to create backup file: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			os.Remove(path)
+			return "", time.Time{}, fmt.Errorf("failed to create backup file: %w", err)
+		}
+		return path, now, nil
+	}
+
+	return "", time.Time{}, fmt.Errorf("failed to find an unused backup name in %s", dir)
+}
+
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+
+	// Timestamps in the names sort chronologically.
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func Rotate(dir string, keep int) error {
+	backups, err := List(dir)
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Restore replaces the SQLite database at target with a backup. The backup
+// is opened and checked first: it must pass an integrity check and must not
+// be newer than the migrations this binary ships. The previous database is
+// kept next to the target, together with any WAL and shared-memory files,
+// which SQLite would otherwise replay over the restored database. The service
+// must be stopped while restoring.
+func Restore(backupPath, target string) (string, error) {
+	if err := verify(backupPath); err != nil {
+		return "", err
+	}
+
+	staged := target + ".restoring"
+	if err := copyFile(backupPath, staged); err != nil {
+		return "", err
+	}
+
+	previous := target + ".pre-restore-" + time.Now().UTC().Format(timeFormat)
+	moved := false
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		if _, err := os.Stat(target + suffix); err != nil {
+			continue
+		}
+		if err := os.Rename(target+suffix, previous+suffix); err != nil {
+			os.Remove(staged)
+			return "", fmt.Errorf("failed to move current database aside: %w", err)
+		}
+		moved = moved || suffix == ""
+	}
+	if !moved {
+		previous = ""
+	}
+
+	if err := os.Rename(staged, target); err != nil {
+		return "", fmt.Errorf("failed to swap in backup: %w", err)
+	}
+
+	return previous, nil
+}
+
+func verify(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup not readable: %w", err)
+	}
+
+	db, err := database.Open(config.DatabaseConfig{Driver: database.DriverSQLite, Path: path})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.Get(&integrity, `PRAGMA integrity_check`); err != nil {
+		return fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", integrity)
+	}
+
+	version, err := database.SchemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("backup has no schema version: %w", err)
+	}
+	latest, err := database.LatestVersion()
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("%w: backup is at version %d, binary supports up to %d", database.ErrSchemaTooNew, version, latest)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	return out.Close()
+}
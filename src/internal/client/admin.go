@@ -0,0 +1,97 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"regression-ci/pkg/types"
+)
+
+func (c *Client) repoURL(repo string) string {
+	return c.baseURL + "/repos/" + url.PathEscape(repo)
+}
+
+func (c *Client) adminRepoURL(repo string) string {
+	return c.baseURL + "/admin/repos/" + url.PathEscape(repo)
+}
+
+func seriesQuery(metric string) string {
+	if metric == "" {
+		return ""
+	}
+	return "?" + url.Values{"metric": {metric}}.Encode()
+}
+
+func (c *Client) Repos(ctx context.Context) ([]types.RepoSummary, error) {
+	var repos []types.RepoSummary
+	if err := c.get(ctx, c.baseURL+"/admin/repos", &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (c *Client) Series(ctx context.Context, repo string) ([]types.SeriesSummary, error) {
+	var series []types.SeriesSummary
+	if err := c.get(ctx, c.adminRepoURL(repo)+"/series", &series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// History returns up to limit of a series' most recent samples, newest
+// first.
+func (c *Client) History(ctx context.Context, repo, component, metric string, limit int) ([]types.Benchmark, error) {
+	q := url.Values{"limit": {strconv.Itoa(limit)}}
+	if metric != "" {
+		q.Set("metric", metric)
+	}
+
+	var history []types.Benchmark
+	u := c.adminRepoURL(repo) + "/history/" + url.PathEscape(component) + "?" + q.Encode()
+	if err := c.get(ctx, u, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (c *Client) ResetBaseline(ctx context.Context, repo, component, metric string) error {
+	u := c.adminRepoURL(repo) + "/baselines/" + url.PathEscape(component) + seriesQuery(metric)
+	return c.send(ctx, http.MethodDelete, u, nil, nil)
+}
+
+func (c *Client) PinBaseline(ctx context.Context, repo, component, metric, commit string) (*types.BaselineProvenance, error) {
+	var provenance types.BaselineProvenance
+	u := c.adminRepoURL(repo) + "/baselines/" + url.PathEscape(component) + "/pin" + seriesQuery(metric)
+	if err := c.send(ctx, http.MethodPost, u, types.PinBaselineRequest{Commit: commit}, &provenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
+
+// Reanalyze has the server analyse the latest run of a commit again.
+func (c *Client) Reanalyze(ctx context.Context, repo, commit string) (*types.AnalyzeResponse, error) {
+	var resp types.AnalyzeResponse
+	u := c.adminRepoURL(repo) + "/commits/" + url.PathEscape(commit) + "/reanalyze"
+	if err := c.send(ctx, http.MethodPost, u, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) DeleteRun(ctx context.Context, id int64) (*types.DeletedRun, error) {
+	var deleted types.DeletedRun
+	u := c.baseURL + "/admin/runs/" + strconv.FormatInt(id, 10)
+	if err := c.send(ctx, http.MethodDelete, u, nil, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+func (c *Client) Vacuum(ctx context.Context) error {
+	return c.send(ctx, http.MethodPost, c.baseURL+"/admin/vacuum", nil, nil)
+}
@@ -0,0 +1,302 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+
+// Package client talks to a regression-ci server over its HTTP API.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"regression-ci/pkg/types"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// APIError is a non-2xx answer from the server.
+type APIError struct {
+	Status   int
+	Message  string
+	Problems []string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+	if len(e.Problems) > 0 {
+		msg += " (" + strings.Join(e.Problems, "; ") + ")"
+	}
+	return msg
+}
+
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New returns a client for the server at baseURL. The token is sent as a
+// bearer token and is only needed for the admin API.
+func New(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Run describes where uploaded results were measured.
+type Run struct {
+	Repo        string
+	Branch      string
+	Commit      string
+	PRNumber    int
+	CIURL       string
+	Runner      string
+	BisectionID int64
+}
+
+func (r Run) query() url.Values {
+	q := url.Values{}
+	q.Set("repo", r.Repo)
+	q.Set("branch", r.Branch)
+	q.Set("commit", r.Commit)
+	if r.PRNumber > 0 {
+		q.Set("pr_number", strconv.Itoa(r.PRNumber))
+	}
+	if r.CIURL != "" {
+		q.Set("ci_url", r.CIURL)
+	}
+	if r.Runner != "" {
+		q.Set("runner", r.Runner)
+	}
+	if r.BisectionID > 0 {
+		q.Set("bisection_id", strconv.FormatInt(r.BisectionID, 10))
+	}
+	return q
+}
+
+// Profile is a pprof file taken for one component.
+type Profile struct {
+	Component string
+	Path      string
+}
+
+// Upload is benchmark tool output to send to /ingest, optionally with
+// artifacts and profiles, which turn it into a multipart upload.
+type Upload struct {
+	Run         Run
+	Format      string
+	ContentType string
+	Results     string
+	Artifacts   []string
+	Profiles    []Profile
+	// Compress gzips the request body.
+	Compress bool
+}
+
+func (u Upload) Multipart() bool {
+	return len(u.Artifacts) > 0 || len(u.Profiles) > 0
+}
+
+// IngestURL is where the upload is posted.
+func (c *Client) IngestURL(u Upload) string {
+	q := u.Run.query()
+	if u.Format != "" {
+		q.Set("format", u.Format)
+	}
+	return c.baseURL + "/ingest?" + q.Encode()
+}
+
+// Ingest streams an upload to the server and returns its analysis.
+func (c *Client) Ingest(ctx context.Context, u Upload) (*types.AnalyzeResponse, error) {
+	body, writer := io.Pipe()
+	contentType := u.ContentType
+	var write func(io.Writer) error
+	if u.Multipart() {
+		mw := multipart.NewWriter(nil)
+		contentType = mw.FormDataContentType()
+		write = func(w io.Writer) error {
+			return writeMultipart(w, mw.Boundary(), u)
+		}
+	} else {
+		write = func(w io.Writer) error {
+			return copyFile(w, u.Results)
+		}
+	}
+
+	go func() {
+		var w io.Writer = writer
+		var gz *gzip.Writer
+		if u.Compress {
+			gz = gzip.NewWriter(writer)
+			w = gz
+		}
+		err := write(w)
+		if err == nil && gz != nil {
+			err = gz.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.IngestURL(u), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if u.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	var resp types.AnalyzeResponse
+	if err := c.do(req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func writeMultipart(w io.Writer, boundary string, u Upload) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="results"; filename=%q`, filepath.Base(u.Results)))
+	header.Set("Content-Type", u.ContentType)
+	if err := writePart(mw, header, u.Results); err != nil {
+		return err
+	}
+
+	for _, path := range u.Artifacts {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="artifact"; filename=%q`, filepath.Base(path)))
+		header.Set("Content-Type", "application/octet-stream")
+		if err := writePart(mw, header, path); err != nil {
+			return err
+		}
+	}
+
+	for _, profile := range u.Profiles {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="profile[%s]"; filename=%q`,
+			profile.Component, filepath.Base(profile.Path)))
+		header.Set("Content-Type", "application/octet-stream")
+		if err := writePart(mw, header, profile.Path); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+func writePart(mw *multipart.Writer, header textproto.MIMEHeader, path string) error {
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	return copyFile(part, path)
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// CommitAnalysisURL is where the latest analysis of a commit is read.
+func (c *Client) CommitAnalysisURL(repo, commit string) string {
+	return c.repoURL(repo) + "/commits/" + url.PathEscape(commit) + "/analysis"
+}
+
+// CommitAnalysis returns the analysis of the most recent run of a commit,
+// or ErrNotFound when no run has been analyzed yet.
+func (c *Client) CommitAnalysis(ctx context.Context, repo, commit string) (*types.AnalyzeResponse, error) {
+	var resp types.AnalyzeResponse
+	if err := c.get(ctx, c.CommitAnalysisURL(repo, commit), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) get(ctx context.Context, url string, v interface{}) error {
+	return c.send(ctx, http.MethodGet, url, nil, v)
+}
+
+// send makes a request with body, when not nil, encoded as JSON.
+func (c *Client) send(ctx context.Context, method, url string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, v)
+}
+
+// do sends a request and decodes a JSON answer into v, turning error
+// answers into an *APIError, or ErrNotFound for a 404.
+func (c *Client) do(req *http.Request, v interface{}) error {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error    string   `json:"error"`
+			Problems []string `json:"problems"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		apiErr := &APIError{Status: resp.StatusCode, Message: body.Error, Problems: body.Problems}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %v", ErrNotFound, apiErr)
+		}
+		return apiErr
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -4,16 +4,31 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 )
 
+const EnvPrefix = "REGRESSION_CI"
+
 type Config struct {
 	Server    ServerConfig    `mapstructure:"server"`
 	Database  DatabaseConfig  `mapstructure:"database"`
 	GitHub    GitHubConfig    `mapstructure:"github"`
 	Detection DetectionConfig `mapstructure:"detection"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Retention RetentionConfig `mapstructure:"retention"`
+	Backup    BackupConfig    `mapstructure:"backup"`
+	Admin     AdminConfig     `mapstructure:"admin"`
+	Ingest    IngestConfig    `mapstructure:"ingest"`
+	Artifacts ArtifactsConfig `mapstructure:"artifacts"`
 }
 
 type ServerConfig struct {
@@ -23,37 +38,120 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
-	Path string `mapstructure:"path"`
+	Driver string `mapstructure:"driver"`
+	Path   string `mapstructure:"path"`
+	DSN    string `mapstructure:"dsn"`
 }
 
 type GitHubConfig struct {
-	WebhookSecret string `mapstructure:"webhook_secret"`
-	Token         string `mapstructure:"token"`
-	AppID         int64  `mapstructure:"app_id"`
-	PrivateKey    string `mapstructure:"private_key"`
+	WebhookSecret  string `mapstructure:"webhook_secret"`
+	Token          string `mapstructure:"token"`
+	AppID          int64  `mapstructure:"app_id"`
+	PrivateKey     string `mapstructure:"private_key"`
+	APIURL         string `mapstructure:"api_url"`
+	BisectDispatch bool   `mapstructure:"bisect_dispatch"`
 }
 
+// DetectionConfig holds the default thresholds. A baseline is near zero, and
+// judged by AbsoluteThreshold, when DefaultThreshold percent of it would be
+// smaller than AbsoluteThreshold.
 type DetectionConfig struct {
-	DefaultThreshold float64 `mapstructure:"default_threshold"`
-	MinSamples       int     `mapstructure:"min_samples"`
-	MaxSamples       int     `mapstructure:"max_samples"`
+	DefaultThreshold  float64 `mapstructure:"default_threshold"`
+	AbsoluteThreshold float64 `mapstructure:"absolute_threshold"`
+	StrictFactor      float64 `mapstructure:"strict_factor"`
+	MinSamples        int     `mapstructure:"min_samples"`
+	MaxSamples        int     `mapstructure:"max_samples"`
+	// SignificanceLevel is the p-value below which a change measured with
+	// repeated samples counts as real. Zero disables the significance test.
+	SignificanceLevel float64 `mapstructure:"significance_level"`
+}
+
+// RetentionConfig controls downsampling of old raw samples. A RawDays of
+// zero keeps every sample forever.
+type RetentionConfig struct {
+	RawDays        int           `mapstructure:"raw_days"`
+	Granularity    string        `mapstructure:"granularity"`
+	Interval       time.Duration `mapstructure:"interval"`
+	VacuumInterval time.Duration `mapstructure:"vacuum_interval"`
+}
+
+// BackupConfig schedules online SQLite backups. An Interval of zero disables
+// scheduled backups; on-demand backups through the admin API still work.
+type BackupConfig struct {
+	Dir      string        `mapstructure:"dir"`
+	Interval time.Duration `mapstructure:"interval"`
+	Keep     int           `mapstructure:"keep"`
+}
+
+type AdminConfig struct {
+	Token string `mapstructure:"token"`
+}
+
+// IngestConfig bounds uploads. MaxBodyBytes applies to the request body
+// both as sent and after Content-Encoding is decoded.
+type IngestConfig struct {
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+}
+
+// ArtifactsConfig is where logs and profiles attached to runs are kept.
+type ArtifactsConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+type LoggingConfig struct {
+	Level string `mapstructure:"level"`
+}
+
+// DefaultDetection is the detection configuration of a server that sets
+// none, for tools that analyse results without one.
+func DefaultDetection() DetectionConfig {
+	return DetectionConfig{
+		DefaultThreshold:  10.0,
+		AbsoluteThreshold: 0.5,
+		StrictFactor:      0.5,
+		MinSamples:        5,
+		MaxSamples:        50,
+		SignificanceLevel: 0.05,
+	}
 }
 
 func Load() (*Config, error) {
 	viper.SetDefault("server.address", ":8080")
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
+	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.path", "./regression.db")
-	viper.SetDefault("detection.default_threshold", 10.0)
-	viper.SetDefault("detection.min_samples", 5)
-	viper.SetDefault("detection.max_samples", 50)
+	detection := DefaultDetection()
+	viper.SetDefault("detection.default_threshold", detection.DefaultThreshold)
+	viper.SetDefault("detection.absolute_threshold", detection.AbsoluteThreshold)
+	viper.SetDefault("detection.strict_factor", detection.StrictFactor)
+	viper.SetDefault("detection.min_samples", detection.MinSamples)
+	viper.SetDefault("detection.max_samples", detection.MaxSamples)
+	viper.SetDefault("detection.significance_level", detection.SignificanceLevel)
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("retention.raw_days", 0)
+	viper.SetDefault("retention.granularity", "commit")
+	viper.SetDefault("retention.interval", "1h")
+	viper.SetDefault("retention.vacuum_interval", "168h")
+	viper.SetDefault("backup.dir", "./backups")
+	viper.SetDefault("backup.interval", "0s")
+	viper.SetDefault("backup.keep", 7)
+	viper.SetDefault("ingest.max_body_bytes", 64<<20)
+	viper.SetDefault("artifacts.dir", "./artifacts")
 
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("./config")
 
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
+	for key := range flatten(reflect.ValueOf(Config{}), "") {
+		if err := viper.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("failed to bind %s: %w", key, err)
+		}
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -61,10 +159,159 @@ func Load() (*Config, error) {
 		}
 	}
 
+	return unmarshal()
+}
+
+func unmarshal() (*Config, error) {
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
 
+	if err := config.readSecretFiles(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
-}
\ No newline at end of file
+}
+
+// EnvName returns the environment variable that overrides a config key, e.g.
+// github.webhook_secret is read from REGRESSION_CI_GITHUB_WEBHOOK_SECRET.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readSecretFiles lets secrets be mounted as files: when <ENV>_FILE is set,
+// the file's contents take precedence over any inline value.
+func (c *Config) readSecretFiles() error {
+	secrets := map[string]*string{
+		"database.dsn":          &c.Database.DSN,
+		"github.webhook_secret": &c.GitHub.WebhookSecret,
+		"github.token":          &c.GitHub.Token,
+		"github.private_key":    &c.GitHub.PrivateKey,
+		"admin.token":           &c.Admin.Token,
+	}
+
+	for key, target := range secrets {
+		path := os.Getenv(EnvName(key) + "_FILE")
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", key, path, err)
+		}
+		*target = strings.TrimRight(string(data), "\r\n")
+	}
+
+	return nil
+}
+
+// Validate reports every problem with the configuration at once, so a broken
+// deployment can be fixed in a single pass.
+func (c *Config) Validate() error {
+	var problems []error
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Address == "" {
+		problem("server.address must not be empty")
+	}
+	if c.Server.ReadTimeout <= 0 {
+		problem("server.read_timeout must be positive, got %s", c.Server.ReadTimeout)
+	}
+	if c.Server.WriteTimeout <= 0 {
+		problem("server.write_timeout must be positive, got %s", c.Server.WriteTimeout)
+	}
+
+	switch c.Database.Driver {
+	case "sqlite":
+		if c.Database.Path == "" {
+			problem("database.path must not be empty for the sqlite driver")
+		}
+	case "postgres":
+		if c.Database.DSN == "" {
+			problem("database.dsn must not be empty for the postgres driver")
+		}
+	default:
+		problem("database.driver must be sqlite or postgres, got %q", c.Database.Driver)
+	}
+
+	if (c.GitHub.AppID != 0) != (c.GitHub.PrivateKey != "") {
+		problem("github.app_id and github.private_key must be set together")
+	}
+	if c.GitHub.APIURL != "" {
+		if u, err := url.Parse(c.GitHub.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			problem("github.api_url %q is not an absolute URL", c.GitHub.APIURL)
+		}
+	}
+
+	if c.Detection.DefaultThreshold <= 0 {
+		problem("detection.default_threshold must be positive, got %v", c.Detection.DefaultThreshold)
+	}
+	if c.Detection.AbsoluteThreshold <= 0 {
+		problem("detection.absolute_threshold must be positive, got %v", c.Detection.AbsoluteThreshold)
+	}
+	if c.Detection.StrictFactor <= 0 || c.Detection.StrictFactor > 1 {
+		problem("detection.strict_factor must be in (0, 1], got %v", c.Detection.StrictFactor)
+	}
+	if c.Detection.MinSamples < 1 {
+		problem("detection.min_samples must be at least 1, got %d", c.Detection.MinSamples)
+	}
+	if c.Detection.MaxSamples < c.Detection.MinSamples {
+		problem("detection.max_samples (%d) must not be less than detection.min_samples (%d)",
+			c.Detection.MaxSamples, c.Detection.MinSamples)
+	}
+	if c.Detection.SignificanceLevel < 0 || c.Detection.SignificanceLevel >= 1 {
+		problem("detection.significance_level must be in [0, 1), got %v", c.Detection.SignificanceLevel)
+	}
+
+	if c.Retention.RawDays < 0 {
+		problem("retention.raw_days must not be negative, got %d", c.Retention.RawDays)
+	}
+	if c.Retention.RawDays > 0 {
+		if c.Retention.Granularity != "commit" && c.Retention.Granularity != "day" {
+			problem("retention.granularity must be commit or day, got %q", c.Retention.Granularity)
+		}
+		if c.Retention.Interval <= 0 {
+			problem("retention.interval must be positive, got %s", c.Retention.Interval)
+		}
+		if c.Retention.VacuumInterval < 0 {
+			problem("retention.vacuum_interval must not be negative, got %s", c.Retention.VacuumInterval)
+		}
+	}
+
+	if c.Backup.Interval < 0 {
+		problem("backup.interval must not be negative, got %s", c.Backup.Interval)
+	}
+	if c.Backup.Keep < 0 {
+		problem("backup.keep must not be negative, got %d", c.Backup.Keep)
+	}
+	if c.Backup.Interval > 0 {
+		if c.Backup.Dir == "" {
+			problem("backup.dir must be set when backup.interval is enabled")
+		}
+		if c.Database.Driver != "sqlite" {
+			problem("scheduled backups require the sqlite driver; back up postgres with pg_dump")
+		}
+	}
+
+	if c.Ingest.MaxBodyBytes <= 0 {
+		problem("ingest.max_body_bytes must be positive, got %d", c.Ingest.MaxBodyBytes)
+	}
+	if c.Artifacts.Dir == "" {
+		problem("artifacts.dir must not be empty")
+	}
+
+	if _, err := zerolog.ParseLevel(c.Logging.Level); err != nil {
+		problem("logging.level %q is not a valid level", c.Logging.Level)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(problems...))
+	}
+
+	return nil
+}
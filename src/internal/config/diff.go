@@ -0,0 +1,92 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const redacted = "<redacted>"
+
+var secretKeys = map[string]bool{
+	"database.dsn":          true,
+	"github.webhook_secret": true,
+	"github.token":          true,
+	"github.private_key":    true,
+	"admin.token":           true,
+}
+
+// Change is one setting that differs between two configs. Restart marks
+// settings that only take effect when the service restarts.
+type Change struct {
+	Key     string `json:"key"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Restart bool   `json:"restart,omitempty"`
+}
+
+// RestartRequired reports whether a setting is only read at startup.
+func RestartRequired(key string) bool {
+	return strings.HasPrefix(key, "server.") || strings.HasPrefix(key, "database.")
+}
+
+// Diff lists every leaf setting that differs between two configs, keyed by
+// its config-file path. Secret values are never included.
+func Diff(old, new *Config) []Change {
+	before := flatten(reflect.ValueOf(*old), "")
+	after := flatten(reflect.ValueOf(*new), "")
+
+	var changes []Change
+	for key, value := range after {
+		if before[key] == value {
+			continue
+		}
+
+		change := Change{Key: key, Old: before[key], New: value, Restart: RestartRequired(key)}
+		if secretKeys[key] {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+func flatten(v reflect.Value, prefix string) map[string]string {
+	values := make(map[string]string)
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && value.Type().PkgPath() == v.Type().PkgPath() {
+			for k, nested := range flatten(value, key) {
+				values[k] = nested
+			}
+			continue
+		}
+
+		values[key] = fmt.Sprint(value.Interface())
+	}
+
+	return values
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RepoFileName    = ".regression-ci.yml"
+	RepoFileVersion = 1
+)
+
+// RepoFile is the repository-owned configuration. Unset fields fall through
+// to the config table and then to DetectionConfig defaults.
+type RepoFile struct {
+	Version           int                          `yaml:"version" json:"version"`
+	Enabled           *bool                        `yaml:"enabled" json:"enabled,omitempty"`
+	ThresholdPercent  *float64                     `yaml:"threshold_percent" json:"threshold_percent,omitempty"`
+	AbsoluteThreshold *float64                     `yaml:"absolute_threshold" json:"absolute_threshold,omitempty"`
+	MinSamples        *int                         `yaml:"min_samples" json:"min_samples,omitempty"`
+	Components        map[string]RepoFileComponent `yaml:"components" json:"components,omitempty"`
+}
+
+type RepoFileComponent struct {
+	Enabled           *bool    `yaml:"enabled" json:"enabled,omitempty"`
+	ThresholdPercent  *float64 `yaml:"threshold_percent" json:"threshold_percent,omitempty"`
+	AbsoluteThreshold *float64 `yaml:"absolute_threshold" json:"absolute_threshold,omitempty"`
+}
+
+func ParseRepoFile(data []byte) (*RepoFile, []string) {
+	var file RepoFile
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, []string{fmt.Sprintf("%s: %v", RepoFileName, err)}
+	}
+
+	if problems := file.Validate(); len(problems) > 0 {
+		return nil, problems
+	}
+
+	if file.Version == 0 {
+		file.Version = RepoFileVersion
+	}
+
+	return &file, nil
+}
+
+func (f *RepoFile) Validate() []string {
+	var problems []string
+
+	if f.Version != 0 && f.Version != RepoFileVersion {
+		problems = append(problems, fmt.Sprintf("version: unsupported version %d, expected %d", f.Version, RepoFileVersion))
+	}
+
+	problems = append(problems, validateThreshold("threshold_percent", f.ThresholdPercent)...)
+	problems = append(problems, validateThreshold("absolute_threshold", f.AbsoluteThreshold)...)
+
+	if f.MinSamples != nil && *f.MinSamples < 1 {
+		problems = append(problems, fmt.Sprintf("min_samples: must be at least 1, got %d", *f.MinSamples))
+	}
+
+	names := make([]string, 0, len(f.Components))
+	for name := range f.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" {
+			problems = append(problems, "components: component name must not be empty")
+			continue
+		}
+		component := f.Components[name]
+		prefix := "components." + name + "."
+		problems = append(problems, validateThreshold(prefix+"threshold_percent", component.ThresholdPercent)...)
+		problems = append(problems, validateThreshold(prefix+"absolute_threshold", component.AbsoluteThreshold)...)
+	}
+
+	return problems
+}
+
+func validateThreshold(field string, value *float64) []string {
+	if value == nil {
+		return nil
+	}
+	if math.IsNaN(*value) || math.IsInf(*value, 0) || *value <= 0 {
+		return []string{fmt.Sprintf("%s: must be a positive number, got %v", field, *value)}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// Watch reloads the config file whenever it changes on disk and hands every
+// successfully parsed result to onChange. Validation and swapping are left to
+// the caller so a bad edit never reaches running components. It reports
+// whether a config file is being watched at all.
+func Watch(onChange func(*Config)) bool {
+	if viper.ConfigFileUsed() == "" {
+		return false
+	}
+
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		cfg, err := unmarshal()
+		if err != nil {
+			log.Error().Err(err).Str("file", event.Name).Msg("failed to reload configuration")
+			return
+		}
+		onChange(cfg)
+	})
+	viper.WatchConfig()
+
+	return true
+}
+
+func ApplyLogLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(parsed)
+	return nil
+}
@@ -6,55 +6,64 @@ package database
 import (
 	"fmt"
 
-	"github.com/jmoiron/sqlx"
 	_ "github.com/glebarez/go-sqlite"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/config"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS benchmarks (
-	id INTEGER PRIMARY KEY,
-	repo TEXT NOT NULL,
-	branch TEXT NOT NULL,
-	commit_hash TEXT NOT NULL,
-	component TEXT NOT NULL,
-	value REAL NOT NULL,
-	timestamp INTEGER NOT NULL
-);
-
-CREATE TABLE IF NOT EXISTS baselines (
-	repo TEXT NOT NULL,
-	component TEXT NOT NULL,
-	baseline_value REAL NOT NULL,
-	sample_count INTEGER DEFAULT 5,
-	updated_at INTEGER NOT NULL,
-	PRIMARY KEY (repo, component)
-);
-
-CREATE TABLE IF NOT EXISTS config (
-	repo TEXT PRIMARY KEY,
-	threshold_percent REAL DEFAULT 10.0,
-	min_samples INTEGER DEFAULT 5,
-	enabled BOOLEAN DEFAULT 1
-);
-
-CREATE INDEX IF NOT EXISTS idx_benchmarks_repo_component ON benchmarks(repo, component);
-CREATE INDEX IF NOT EXISTS idx_benchmarks_timestamp ON benchmarks(timestamp);
-`
-
-func Init(path string) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("sqlite", path)
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+var serialColumns = map[string]string{
+	DriverSQLite:   "INTEGER PRIMARY KEY",
+	DriverPostgres: "BIGSERIAL PRIMARY KEY",
+}
+
+func Init(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("database connection failed: %w", err)
+		return nil, err
 	}
 
-	if err := createTables(db); err != nil {
-		return nil, fmt.Errorf("table creation failed: %w", err)
+	applied, err := Migrate(db)
+	for _, migration := range applied {
+		log.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("applied migration")
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return db, nil
 }
 
-func createTables(db *sqlx.DB) error {
-	_, err := db.Exec(schema)
-	return err
-}
\ No newline at end of file
+// Open connects without migrating, for tools that inspect a database.
+func Open(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	driver, source := cfg.Driver, cfg.Path
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	if driver == DriverPostgres {
+		source = cfg.DSN
+	}
+	if _, ok := serialColumns[driver]; !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	db, err := sqlx.Connect(driver, source)
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+
+	// Every connection to :memory: opens a separate, empty database.
+	if driver == DriverSQLite && source == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
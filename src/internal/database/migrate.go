@@ -0,0 +1,174 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Serializes migrations across replicas sharing one Postgres database.
+const migrationLockID = 72057594037927
+
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations returns the embedded migrations in version order. Files are
+// named NNNN_description.sql and may use {{serial}} for an auto-increment
+// primary key column, which is the one construct the dialects disagree on.
+func Migrations() ([]Migration, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(names))
+	seen := make(map[int]string)
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".sql")
+		prefix, description, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		data, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: description, SQL: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion is the schema version this binary migrates databases to.
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return latestVersion(migrations), nil
+}
+
+func latestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func SchemaVersion(db *sqlx.DB) (int, error) {
+	var version int
+	if err := db.Get(&version, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Migrate applies every pending migration, each in its own transaction, and
+// returns the ones it applied. It refuses to touch a database that has
+// already been migrated past the newest migration this binary knows about.
+func Migrate(db *sqlx.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestVersion(migrations)
+	if current > latest {
+		return nil, fmt.Errorf("%w: database is at version %d, binary supports up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	var applied []Migration
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		ran, err := applyMigration(db, migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+func applyMigration(db *sqlx.DB, migration Migration) (bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if db.DriverName() == DriverPostgres {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return false, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	}
+
+	// Another replica may have applied it while we waited for the lock.
+	var count int
+	if err := tx.Get(&count, tx.Rebind(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), migration.Version); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	statements := strings.ReplaceAll(migration.SQL, "{{serial}}", serialColumns[db.DriverName()])
+	if _, err := tx.Exec(statements); err != nil {
+		return false, err
+	}
+
+	query := tx.Rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`)
+	if _, err := tx.Exec(query, migration.Version, migration.Name, time.Now().Unix()); err != nil {
+		return false, fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,107 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+CREATE TABLE IF NOT EXISTS benchmarks (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	component TEXT NOT NULL,
+	value DOUBLE PRECISION NOT NULL,
+	timestamp BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS baselines (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	baseline_value DOUBLE PRECISION NOT NULL,
+	sample_count INTEGER DEFAULT 5,
+	updated_at BIGINT NOT NULL,
+	PRIMARY KEY (repo, component)
+);
+
+CREATE TABLE IF NOT EXISTS baseline_sources (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	source TEXT NOT NULL,
+	estimator TEXT NOT NULL,
+	pinned_commit TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (repo, component)
+);
+
+CREATE TABLE IF NOT EXISTS baseline_samples (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	benchmark_id BIGINT NOT NULL,
+	PRIMARY KEY (repo, component, benchmark_id)
+);
+
+CREATE TABLE IF NOT EXISTS config (
+	repo TEXT PRIMARY KEY,
+	threshold_percent DOUBLE PRECISION DEFAULT 10.0,
+	min_samples INTEGER DEFAULT 5,
+	enabled BOOLEAN DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS pr_reports (
+	repo TEXT NOT NULL,
+	pr_number INTEGER NOT NULL,
+	head_sha TEXT NOT NULL,
+	check_run_id BIGINT NOT NULL DEFAULT 0,
+	response TEXT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	PRIMARY KEY (repo, pr_number)
+);
+
+CREATE TABLE IF NOT EXISTS acknowledgements (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	pr_number INTEGER NOT NULL,
+	component TEXT NOT NULL,
+	action TEXT NOT NULL,
+	author TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pr_labels (
+	repo TEXT NOT NULL,
+	pr_number INTEGER NOT NULL,
+	label TEXT NOT NULL,
+	PRIMARY KEY (repo, pr_number, label)
+);
+
+CREATE TABLE IF NOT EXISTS bisections (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	component TEXT NOT NULL,
+	good_commit TEXT NOT NULL,
+	bad_commit TEXT NOT NULL,
+	good_value DOUBLE PRECISION NOT NULL,
+	bad_value DOUBLE PRECISION NOT NULL,
+	candidates TEXT NOT NULL,
+	low INTEGER NOT NULL,
+	high INTEGER NOT NULL,
+	pending_commit TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	culprit TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS repo_files (
+	repo TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	source TEXT NOT NULL,
+	content TEXT NOT NULL DEFAULT '',
+	problems TEXT NOT NULL DEFAULT '[]',
+	fetched_at BIGINT NOT NULL,
+	PRIMARY KEY (repo, commit_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_benchmarks_repo_component ON benchmarks(repo, component);
+CREATE INDEX IF NOT EXISTS idx_benchmarks_timestamp ON benchmarks(timestamp);
+CREATE INDEX IF NOT EXISTS idx_acknowledgements_pr ON acknowledgements(repo, pr_number);
+CREATE INDEX IF NOT EXISTS idx_bisections_repo_status ON bisections(repo, status);
@@ -0,0 +1,21 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+CREATE TABLE runs (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	pr_number INTEGER NOT NULL DEFAULT 0,
+	ci_url TEXT NOT NULL DEFAULT '',
+	runner TEXT NOT NULL DEFAULT '',
+	started_at BIGINT NOT NULL,
+	finished_at BIGINT NOT NULL,
+	metadata TEXT NOT NULL DEFAULT '{}'
+);
+
+ALTER TABLE benchmarks ADD COLUMN run_id BIGINT;
+
+CREATE INDEX idx_runs_repo_commit ON runs(repo, commit_hash);
+CREATE INDEX idx_benchmarks_run ON benchmarks(run_id);
@@ -0,0 +1,27 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+CREATE TABLE analysis_results (
+	id {{serial}},
+	run_id BIGINT NOT NULL,
+	repo TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	pr_number INTEGER NOT NULL DEFAULT 0,
+	component TEXT NOT NULL,
+	is_regression BOOLEAN NOT NULL DEFAULT FALSE,
+	current_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+	baseline_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+	percent_change DOUBLE PRECISION NOT NULL DEFAULT 0,
+	absolute_change DOUBLE PRECISION NOT NULL DEFAULT 0,
+	threshold_mode TEXT NOT NULL DEFAULT '',
+	threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+	confidence_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+	sample_size INTEGER NOT NULL DEFAULT 0,
+	p_value DOUBLE PRECISION,
+	error TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_analysis_results_run ON analysis_results(run_id);
+CREATE INDEX idx_analysis_results_commit ON analysis_results(repo, commit_hash);
@@ -0,0 +1,30 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+CREATE TABLE benchmark_aggregates (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	component TEXT NOT NULL,
+	bucket TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	min_value DOUBLE PRECISION NOT NULL,
+	median_value DOUBLE PRECISION NOT NULL,
+	max_value DOUBLE PRECISION NOT NULL,
+	sample_count INTEGER NOT NULL,
+	first_timestamp BIGINT NOT NULL,
+	last_timestamp BIGINT NOT NULL,
+	UNIQUE (repo, branch, component, bucket)
+);
+
+CREATE INDEX idx_benchmark_aggregates_repo_component ON benchmark_aggregates(repo, component);
+
+-- History reads see downsampled buckets as single samples valued at their
+-- median. Aggregate ids are negated so they never collide with raw rows.
+CREATE VIEW benchmark_history AS
+	SELECT id, repo, branch, commit_hash, component, value, timestamp, run_id
+	FROM benchmarks
+	UNION ALL
+	SELECT -id, repo, branch, commit_hash, component, median_value, last_timestamp, CAST(NULL AS BIGINT)
+	FROM benchmark_aggregates;
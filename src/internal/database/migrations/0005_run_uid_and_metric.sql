@@ -0,0 +1,48 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- Runs get an identity that survives export and import between instances.
+-- Existing runs are keyed by content; new runs get a random uid.
+ALTER TABLE runs ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+UPDATE runs SET uid = 'run:' || repo || ':' || commit_hash || ':' || CAST(started_at AS TEXT) || ':' || CAST(id AS TEXT);
+CREATE UNIQUE INDEX idx_runs_repo_uid ON runs(repo, uid);
+
+ALTER TABLE benchmarks ADD COLUMN metric TEXT NOT NULL DEFAULT 'value';
+
+-- Aggregates are per metric too, which changes their unique key.
+DROP VIEW benchmark_history;
+
+CREATE TABLE benchmark_aggregates_new (
+	id {{serial}},
+	repo TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	component TEXT NOT NULL,
+	metric TEXT NOT NULL DEFAULT 'value',
+	bucket TEXT NOT NULL,
+	commit_hash TEXT NOT NULL,
+	min_value DOUBLE PRECISION NOT NULL,
+	median_value DOUBLE PRECISION NOT NULL,
+	max_value DOUBLE PRECISION NOT NULL,
+	sample_count INTEGER NOT NULL,
+	first_timestamp BIGINT NOT NULL,
+	last_timestamp BIGINT NOT NULL,
+	UNIQUE (repo, branch, component, metric, bucket)
+);
+
+INSERT INTO benchmark_aggregates_new (repo, branch, component, bucket, commit_hash, min_value,
+	median_value, max_value, sample_count, first_timestamp, last_timestamp)
+SELECT repo, branch, component, bucket, commit_hash, min_value, median_value, max_value,
+	sample_count, first_timestamp, last_timestamp
+FROM benchmark_aggregates;
+
+DROP TABLE benchmark_aggregates;
+ALTER TABLE benchmark_aggregates_new RENAME TO benchmark_aggregates;
+CREATE INDEX idx_benchmark_aggregates_repo_component ON benchmark_aggregates(repo, component);
+
+CREATE VIEW benchmark_history AS
+	SELECT id, repo, branch, commit_hash, component, metric, value, timestamp, run_id
+	FROM benchmarks
+	UNION ALL
+	SELECT -id, repo, branch, commit_hash, component, metric, median_value, last_timestamp, CAST(NULL AS BIGINT)
+	FROM benchmark_aggregates;
@@ -0,0 +1,54 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- Baselines are kept per (component, metric), so a component reporting
+-- ns/op, B/op and allocs/op tracks three independent series. Existing rows
+-- belong to the single metric that /analyze components have always had.
+CREATE TABLE baselines_new (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	metric TEXT NOT NULL DEFAULT 'value',
+	baseline_value DOUBLE PRECISION NOT NULL,
+	sample_count INTEGER DEFAULT 5,
+	updated_at BIGINT NOT NULL,
+	PRIMARY KEY (repo, component, metric)
+);
+INSERT INTO baselines_new (repo, component, baseline_value, sample_count, updated_at)
+SELECT repo, component, baseline_value, sample_count, updated_at FROM baselines;
+DROP TABLE baselines;
+ALTER TABLE baselines_new RENAME TO baselines;
+
+CREATE TABLE baseline_sources_new (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	metric TEXT NOT NULL DEFAULT 'value',
+	source TEXT NOT NULL,
+	estimator TEXT NOT NULL,
+	pinned_commit TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (repo, component, metric)
+);
+INSERT INTO baseline_sources_new (repo, component, source, estimator, pinned_commit)
+SELECT repo, component, source, estimator, pinned_commit FROM baseline_sources;
+DROP TABLE baseline_sources;
+ALTER TABLE baseline_sources_new RENAME TO baseline_sources;
+
+CREATE TABLE baseline_samples_new (
+	repo TEXT NOT NULL,
+	component TEXT NOT NULL,
+	metric TEXT NOT NULL DEFAULT 'value',
+	benchmark_id BIGINT NOT NULL,
+	PRIMARY KEY (repo, component, metric, benchmark_id)
+);
+INSERT INTO baseline_samples_new (repo, component, benchmark_id)
+SELECT repo, component, benchmark_id FROM baseline_samples;
+DROP TABLE baseline_samples;
+ALTER TABLE baseline_samples_new RENAME TO baseline_samples;
+
+ALTER TABLE analysis_results ADD COLUMN metric TEXT NOT NULL DEFAULT 'value';
+ALTER TABLE analysis_results ADD COLUMN unit TEXT NOT NULL DEFAULT '';
+ALTER TABLE analysis_results ADD COLUMN direction TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE bisections ADD COLUMN metric TEXT NOT NULL DEFAULT 'value';
+
+CREATE INDEX idx_benchmarks_series ON benchmarks(repo, component, metric);
@@ -0,0 +1,17 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- Files uploaded with a run's results. Only their metadata is kept here; the
+-- content is stored under artifacts.dir by run ID and name.
+CREATE TABLE artifacts (
+	id {{serial}},
+	run_id BIGINT NOT NULL,
+	repo TEXT NOT NULL,
+	name TEXT NOT NULL,
+	content_type TEXT NOT NULL DEFAULT '',
+	size BIGINT NOT NULL,
+	sha256 TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	UNIQUE (run_id, name)
+);
@@ -0,0 +1,10 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- pprof profiles are artifacts taken for one component. kind tells CPU and
+-- memory profiles apart so each is only diffed against its own kind.
+ALTER TABLE artifacts ADD COLUMN component TEXT NOT NULL DEFAULT '';
+ALTER TABLE artifacts ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_artifacts_profiles ON artifacts(repo, component, kind);
@@ -0,0 +1,18 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- Runs dispatched to measure a bisection midpoint record the bisection they
+-- answer. Their samples belong to an older commit measured "now", so they
+-- are kept out of the history that baselines and last-good lookups read.
+ALTER TABLE runs ADD COLUMN bisection_id BIGINT NOT NULL DEFAULT 0;
+
+DROP VIEW benchmark_history;
+
+CREATE VIEW benchmark_history AS
+	SELECT b.id, b.repo, b.branch, b.commit_hash, b.component, b.metric, b.value, b.timestamp, b.run_id
+	FROM benchmarks b
+	WHERE NOT EXISTS (SELECT 1 FROM runs r WHERE r.id = b.run_id AND r.bisection_id != 0)
+	UNION ALL
+	SELECT -id, repo, branch, commit_hash, component, metric, median_value, last_timestamp, CAST(NULL AS BIGINT)
+	FROM benchmark_aggregates;
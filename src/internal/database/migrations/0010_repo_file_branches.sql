@@ -0,0 +1,11 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- A commit without a cached config only falls back to a file pushed to the
+-- same branch or to the default branch, so cached files record both.
+-- Files cached before this have no branch and are never used as a fallback.
+ALTER TABLE repo_files ADD COLUMN branch TEXT NOT NULL DEFAULT '';
+ALTER TABLE repo_files ADD COLUMN default_branch BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_repo_files_branch ON repo_files(repo, source, branch);
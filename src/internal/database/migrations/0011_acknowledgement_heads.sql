@@ -0,0 +1,7 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- An acknowledgement covers the head it was made against, not later pushes
+-- to the PR. Acknowledgements recorded before this have no head and lapse.
+ALTER TABLE acknowledgements ADD COLUMN head_sha TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Copyright 2025 Baleine Jay
+-- Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+-- Commercial use requires a paid license. See link for details.
+
+-- Each verdict records where its baseline came from, and for pinned
+-- baselines the commit it was pinned to.
+ALTER TABLE analysis_results ADD COLUMN baseline_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE analysis_results ADD COLUMN baseline_commit TEXT NOT NULL DEFAULT '';
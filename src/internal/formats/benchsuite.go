@@ -0,0 +1,83 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"io"
+
+	"regression-ci/benchsuite"
+	"regression-ci/pkg/types"
+)
+
+func init() {
+	Register(Format{
+		Name:         "benchsuite",
+		Description:  "SuiteResult JSON written by this service's own cmd/benchmark",
+		ContentTypes: []string{"application/json"},
+		Sniff: func(s *Sample) bool {
+			return !s.Array && s.Keys["go_version"] && s.Keys["results"]
+		},
+		Parse: parseBenchSuite,
+	})
+}
+
+// parseBenchSuite reads the SuiteResult JSON written by cmd/benchmark, so the
+// service can track its own performance. Each component and operation,
+// e.g. "regression/detection", reports the same ns/op, B/op and allocs/op
+// metrics as go test -benchmem. SuiteResult records memory and allocations
+// over all iterations of a benchmark, which are divided back down to per
+// operation figures. The Go version and platform become run metadata.
+func parseBenchSuite(r io.Reader) (*Result, error) {
+	var suite benchsuite.SuiteResult
+	samples := newCollector()
+	dec := json.NewDecoder(r)
+
+	err := decodeObject(dec, map[string]func() error{
+		"go_version": func() error { return decodeValue(dec, &suite.GoVersion) },
+		"goos":       func() error { return decodeValue(dec, &suite.GOOS) },
+		"goarch":     func() error { return decodeValue(dec, &suite.GOARCH) },
+		"results": func() error {
+			return decodeArray(dec, func() error {
+				var result benchsuite.BenchmarkResult
+				if err := decodeValue(dec, &result); err != nil {
+					return err
+				}
+				if result.Component == "" || result.Operation == "" {
+					return nil
+				}
+
+				component := result.Component + "/" + result.Operation
+				samples.add(component, "ns/op", "ns/op", types.DirectionLower, float64(result.Duration.Nanoseconds()))
+				if result.Iterations > 0 {
+					iterations := float64(result.Iterations)
+					samples.add(component, "B/op", "B/op", types.DirectionLower, float64(result.Memory)/iterations)
+					samples.add(component, "allocs/op", "allocs/op", types.DirectionLower,
+						float64(result.Allocations)/iterations)
+				}
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	metadata := types.Metadata{}
+	for key, value := range map[string]string{
+		"go_version": suite.GoVersion,
+		"goos":       suite.GOOS,
+		"goarch":     suite.GOARCH,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: metadata}, nil
+}
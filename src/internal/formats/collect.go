@@ -0,0 +1,33 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import "regression-ci/pkg/types"
+
+// collector accumulates samples per (component, metric) in the order the
+// series were first seen, so repeated -count runs land in one measurement.
+type collector struct {
+	index        map[[2]string]int
+	measurements []types.Measurement
+}
+
+func newCollector() *collector {
+	return &collector{index: make(map[[2]string]int)}
+}
+
+func (c *collector) add(component, metric, unit, direction string, value float64) {
+	key := [2]string{component, metric}
+	i, ok := c.index[key]
+	if !ok {
+		i = len(c.measurements)
+		c.index[key] = i
+		c.measurements = append(c.measurements, types.Measurement{
+			Component: component,
+			Metric:    metric,
+			Unit:      unit,
+			Direction: direction,
+		})
+	}
+	c.measurements[i].Samples = append(c.measurements[i].Samples, value)
+}
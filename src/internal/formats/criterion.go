@@ -0,0 +1,211 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"regression-ci/pkg/types"
+)
+
+type criterionBenchmark struct {
+	dir       string
+	benchmark []byte
+	estimates []byte
+	sample    []byte
+}
+
+type criterionInfo struct {
+	FullID     string           `json:"full_id"`
+	Throughput map[string]int64 `json:"throughput"`
+}
+
+type criterionEstimates struct {
+	Mean struct {
+		PointEstimate float64 `json:"point_estimate"`
+	} `json:"mean"`
+}
+
+type criterionSample struct {
+	Iters []float64 `json:"iters"`
+	Times []float64 `json:"times"`
+}
+
+// criterionThroughput maps the throughput kinds Criterion records in
+// benchmark.json onto the unit of the derived throughput metric.
+var criterionThroughput = map[string]string{
+	"Bytes":        "B/s",
+	"BytesDecimal": "B/s",
+	"Elements":     "elements/s",
+}
+
+func init() {
+	Register(Format{
+		Name:         "criterion",
+		Description:  "tar or tar.gz archive of a Criterion.rs target/criterion directory",
+		ContentTypes: []string{"application/x-tar", "application/gzip", "application/x-gzip"},
+		Sniff: func(s *Sample) bool {
+			return isArchive(s.Head)
+		},
+		Parse: parseCriterion,
+	})
+}
+
+// parseCriterion reads a tar archive, optionally gzipped, of Criterion.rs's
+// target/criterion directory. Only the latest results in each benchmark's
+// new/ directory are used. Every sample in sample.json becomes a "time"
+// sample in nanoseconds per iteration; without sample.json the mean point
+// estimate from estimates.json is the only sample. Benchmarks declaring a
+// throughput also get a higher-is-better "throughput" metric.
+func parseCriterion(r io.Reader) (*Result, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, corruptArchive(err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	benchmarks := make(map[string]*criterionBenchmark)
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, corruptArchive(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		dir, file := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		if path.Base(dir) != "new" {
+			continue
+		}
+		dir = path.Dir(dir)
+
+		var target *[]byte
+		b := benchmarks[dir]
+		if b == nil {
+			b = &criterionBenchmark{dir: dir}
+		}
+		switch file {
+		case "benchmark.json":
+			target = &b.benchmark
+		case "estimates.json":
+			target = &b.estimates
+		case "sample.json":
+			target = &b.sample
+		default:
+			continue
+		}
+
+		if *target, err = io.ReadAll(archive); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, corruptArchive(err))
+		}
+		benchmarks[dir] = b
+	}
+
+	dirs := make([]string, 0, len(benchmarks))
+	for dir := range benchmarks {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	samples := newCollector()
+	for _, dir := range dirs {
+		if err := benchmarks[dir].collect(samples); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: types.Metadata{}}, nil
+}
+
+// corruptArchive blames the upload for a damaged archive, but passes read
+// errors (such as an exceeded size limit) through untouched.
+func corruptArchive(err error) error {
+	if errors.Is(err, tar.ErrHeader) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return err
+}
+
+func (b *criterionBenchmark) collect(samples *collector) error {
+	var info criterionInfo
+	if b.benchmark != nil {
+		if err := json.Unmarshal(b.benchmark, &info); err != nil {
+			return fmt.Errorf("%w: %s/new/benchmark.json: %v", ErrMalformed, b.dir, err)
+		}
+	}
+
+	component := info.FullID
+	if component == "" {
+		component = b.dir
+		if i := strings.LastIndex(component, "criterion/"); i >= 0 {
+			component = component[i+len("criterion/"):]
+		}
+	}
+
+	var times []float64
+	switch {
+	case b.sample != nil:
+		var sample criterionSample
+		if err := json.Unmarshal(b.sample, &sample); err != nil {
+			return fmt.Errorf("%w: %s/new/sample.json: %v", ErrMalformed, b.dir, err)
+		}
+		for i, total := range sample.Times {
+			if i < len(sample.Iters) && sample.Iters[i] > 0 {
+				times = append(times, total/sample.Iters[i])
+			}
+		}
+	case b.estimates != nil:
+		var estimates criterionEstimates
+		if err := json.Unmarshal(b.estimates, &estimates); err != nil {
+			return fmt.Errorf("%w: %s/new/estimates.json: %v", ErrMalformed, b.dir, err)
+		}
+		times = []float64{estimates.Mean.PointEstimate}
+	}
+
+	for _, ns := range times {
+		samples.add(component, "time", "ns", types.DirectionLower, ns)
+	}
+
+	for kind, amount := range info.Throughput {
+		unit, ok := criterionThroughput[kind]
+		if !ok {
+			continue
+		}
+		for _, ns := range times {
+			if ns > 0 {
+				samples.add(component, "throughput", unit, types.DirectionHigher, float64(amount)*1e9/ns)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"regression-ci/pkg/types"
+)
+
+var (
+	// goConfigLine matches the "key: value" lines go test prints before a
+	// package's benchmarks, e.g. "goos: linux" or "pkg: example.com/parser".
+	goConfigLine = regexp.MustCompile(`^([a-z][^\s:]*):\s*(.*)$`)
+	// goProcsSuffix is the -GOMAXPROCS suffix go test appends to names.
+	goProcsSuffix = regexp.MustCompile(`-\d+$`)
+)
+
+func init() {
+	Register(Format{
+		Name:         "gobench",
+		Description:  "go test -bench text output, with -benchmem and -count",
+		ContentTypes: []string{"text/plain"},
+		Sniff: func(s *Sample) bool {
+			return !s.IsJSON() && !isArchive(s.Head)
+		},
+		Parse: parseGoBench,
+	})
+}
+
+// parseGoBench reads the text output of go test -bench, optionally with
+// -benchmem and -count. Each "value unit" pair on a benchmark line becomes
+// a sample of the metric named by the unit (ns/op, B/op, allocs/op, MB/s or
+// anything passed to b.ReportMetric). Components are named after the
+// benchmark without its "Benchmark" prefix and -GOMAXPROCS suffix, qualified
+// by the last element of the package path when go test printed one.
+// Anything else in the output (test logs, PASS, ok lines) is ignored.
+func parseGoBench(r io.Reader) (*Result, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	samples := newCollector()
+	metadata := types.Metadata{}
+	pkg := ""
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if m := goConfigLine.FindStringSubmatch(line); m != nil {
+			if m[1] == "pkg" {
+				pkg = m[2]
+			} else if _, ok := metadata[m[1]]; !ok {
+				metadata[m[1]] = m[2]
+			}
+			continue
+		}
+
+		name, pairs, ok := parseGoBenchLine(line)
+		if !ok {
+			continue
+		}
+
+		component := goBenchComponent(pkg, name)
+		for _, pair := range pairs {
+			samples.add(component, pair.unit, pair.unit, goBenchDirection(pair.unit), pair.value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: metadata}, nil
+}
+
+type goBenchValue struct {
+	value float64
+	unit  string
+}
+
+// parseGoBenchLine splits "BenchmarkName-8  1000  123 ns/op  16 B/op" into
+// its name and value/unit pairs. Lines that only look like benchmarks, such
+// as the bare names printed by go test -v, are rejected.
+func parseGoBenchLine(line string) (string, []goBenchValue, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !isGoBenchName(fields[0]) {
+		return "", nil, false
+	}
+	if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return "", nil, false
+	}
+
+	pairs := make([]goBenchValue, 0, (len(fields)-2)/2)
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return "", nil, false
+		}
+		pairs = append(pairs, goBenchValue{value: value, unit: fields[i+1]})
+	}
+
+	return fields[0], pairs, true
+}
+
+// isGoBenchName follows go test's own rule: "Benchmark" followed by nothing
+// or by a character that is not a lower-case letter.
+func isGoBenchName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Benchmark")
+	if !ok {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLower(r)
+}
+
+func goBenchComponent(pkg, name string) string {
+	name = goProcsSuffix.ReplaceAllString(strings.TrimPrefix(name, "Benchmark"), "")
+	if name == "" {
+		name = "Benchmark"
+	}
+	if pkg == "" {
+		return name
+	}
+	return path.Base(pkg) + "." + name
+}
+
+// goBenchDirection treats rates (MB/s, ops/s, ...) as higher-is-better and
+// everything else go test reports (time, bytes, allocations per op) as
+// lower-is-better.
+func goBenchDirection(unit string) string {
+	if strings.HasSuffix(unit, "/s") {
+		return types.DirectionHigher
+	}
+	return types.DirectionLower
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"regression-ci/pkg/types"
+)
+
+const (
+	googleRunIteration = "iteration"
+	googleRunAggregate = "aggregate"
+)
+
+// googleFields are the keys of a Google Benchmark entry that describe the
+// run rather than measure it. Any other numeric key is a user counter.
+var googleFields = map[string]bool{
+	"name": true, "family_index": true, "per_family_instance_index": true, "run_name": true,
+	"run_type": true, "repetitions": true, "repetition_index": true, "threads": true,
+	"iterations": true, "real_time": true, "cpu_time": true, "time_unit": true,
+	"aggregate_name": true, "aggregate_unit": true, "label": true,
+	"error_occurred": true, "error_message": true, "big_o": true, "rms": true,
+	"bytes_per_second": true, "items_per_second": true,
+}
+
+// googleContext lists the context keys kept as run metadata.
+var googleContext = []string{"host_name", "executable", "num_cpus", "mhz_per_cpu", "library_build_type"}
+
+type googleEntry map[string]interface{}
+
+func (e googleEntry) str(key string) string {
+	s, _ := e[key].(string)
+	return s
+}
+
+func (e googleEntry) num(key string) (float64, bool) {
+	f, ok := e[key].(float64)
+	return f, ok
+}
+
+func init() {
+	Register(Format{
+		Name:         "googlebench",
+		Description:  "Google Benchmark --benchmark_format=json output",
+		ContentTypes: []string{"application/json"},
+		Sniff: func(s *Sample) bool {
+			return !s.Array && s.Keys["context"]
+		},
+		Parse: parseGoogleBenchmark,
+	})
+}
+
+// parseGoogleBenchmark reads the output of a Google Benchmark binary run
+// with --benchmark_format=json (or --benchmark_out). Every repetition of a
+// benchmark becomes a sample of its real_time, cpu_time, throughput and
+// user counter metrics. The mean/median/stddev aggregates are derived from
+// those repetitions and are skipped, unless the binary only reported
+// aggregates, in which case the mean stands in as a single sample.
+func parseGoogleBenchmark(r io.Reader) (*Result, error) {
+	var context map[string]interface{}
+	samples := newCollector()
+	iterated := make(map[string]bool)
+	var means []googleEntry
+	dec := json.NewDecoder(r)
+
+	err := decodeObject(dec, map[string]func() error{
+		"context": func() error { return decodeValue(dec, &context) },
+		"benchmarks": func() error {
+			return decodeArray(dec, func() error {
+				var entry googleEntry
+				if err := decodeValue(dec, &entry); err != nil {
+					return err
+				}
+				if failed, _ := entry["error_occurred"].(bool); failed {
+					return nil
+				}
+
+				if entry.str("run_type") != googleRunAggregate {
+					iterated[googleRunName(entry)] = true
+					addGoogleEntry(samples, entry)
+				} else if entry.str("aggregate_name") == "mean" {
+					means = append(means, entry)
+				}
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range means {
+		if !iterated[googleRunName(entry)] {
+			addGoogleEntry(samples, entry)
+		}
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	metadata := types.Metadata{}
+	for _, key := range googleContext {
+		if value, ok := context[key]; ok {
+			metadata[key] = value
+		}
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: metadata}, nil
+}
+
+func addGoogleEntry(samples *collector, entry googleEntry) {
+	name := googleRunName(entry)
+
+	unit := entry.str("time_unit")
+	if unit == "" {
+		unit = "ns"
+	}
+	for _, metric := range []string{"real_time", "cpu_time"} {
+		if value, ok := entry.num(metric); ok {
+			samples.add(name, metric, unit, types.DirectionLower, value)
+		}
+	}
+	if value, ok := entry.num("bytes_per_second"); ok {
+		samples.add(name, "bytes_per_second", "B/s", types.DirectionHigher, value)
+	}
+	if value, ok := entry.num("items_per_second"); ok {
+		samples.add(name, "items_per_second", "items/s", types.DirectionHigher, value)
+	}
+
+	counters := make([]string, 0, len(entry))
+	for key := range entry {
+		if _, ok := entry.num(key); ok && !googleFields[key] {
+			counters = append(counters, key)
+		}
+	}
+	sort.Strings(counters)
+	for _, counter := range counters {
+		value, _ := entry.num(counter)
+		samples.add(name, counter, "", googleCounterDirection(counter), value)
+	}
+}
+
+// googleRunName groups repetitions and their aggregates. Older versions of
+// the library have no run_name, and suffix aggregate names with "_mean".
+func googleRunName(entry googleEntry) string {
+	if name := entry.str("run_name"); name != "" {
+		return name
+	}
+	name := entry.str("name")
+	if aggregate := entry.str("aggregate_name"); aggregate != "" {
+		name = strings.TrimSuffix(name, "_"+aggregate)
+	}
+	return name
+}
+
+// googleCounterDirection guesses from the counter name, since Google
+// Benchmark does not record which way a user counter should move. Rates
+// set with benchmark::Counter::kIsRate are conventionally named "..._per_second".
+func googleCounterDirection(counter string) string {
+	if strings.HasSuffix(counter, "_per_second") || strings.HasSuffix(counter, "/s") {
+		return types.DirectionHigher
+	}
+	return types.DirectionLower
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"io"
+
+	"regression-ci/pkg/types"
+)
+
+type hyperfineResult struct {
+	Command string    `json:"command"`
+	Mean    float64   `json:"mean"`
+	User    *float64  `json:"user"`
+	System  *float64  `json:"system"`
+	Times   []float64 `json:"times"`
+}
+
+func init() {
+	Register(Format{
+		Name:         "hyperfine",
+		Description:  "hyperfine --export-json results",
+		ContentTypes: []string{"application/json"},
+		Sniff: func(s *Sample) bool {
+			return !s.Array && s.Keys["results"] && !s.Keys["go_version"]
+		},
+		Parse: parseHyperfine,
+	})
+}
+
+// parseHyperfine reads the file written by hyperfine --export-json. Every
+// timed run of a command is a sample of its wall "time" in seconds; the
+// user and system CPU times are only reported as means and contribute one
+// sample each. Components are named by the command, or by --command-name
+// when it was given.
+func parseHyperfine(r io.Reader) (*Result, error) {
+	samples := newCollector()
+	dec := json.NewDecoder(r)
+
+	err := decodeObject(dec, map[string]func() error{
+		"results": func() error {
+			return decodeArray(dec, func() error {
+				var result hyperfineResult
+				if err := decodeValue(dec, &result); err != nil {
+					return err
+				}
+				addHyperfineResult(samples, result)
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: types.Metadata{}}, nil
+}
+
+func addHyperfineResult(samples *collector, result hyperfineResult) {
+	if result.Command == "" {
+		return
+	}
+
+	times := result.Times
+	if len(times) == 0 {
+		times = []float64{result.Mean}
+	}
+	for _, value := range times {
+		samples.add(result.Command, "time", "s", types.DirectionLower, value)
+	}
+	if result.User != nil {
+		samples.add(result.Command, "user", "s", types.DirectionLower, *result.User)
+	}
+	if result.System != nil {
+		samples.add(result.Command, "system", "s", types.DirectionLower, *result.System)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"regression-ci/pkg/types"
+)
+
+type jmhMetric struct {
+	Score     float64     `json:"score"`
+	ScoreUnit string      `json:"scoreUnit"`
+	RawData   [][]float64 `json:"rawData"`
+}
+
+type jmhResult struct {
+	JMHVersion       string               `json:"jmhVersion"`
+	Benchmark        string               `json:"benchmark"`
+	Mode             string               `json:"mode"`
+	JDKVersion       string               `json:"jdkVersion"`
+	VMName           string               `json:"vmName"`
+	VMVersion        string               `json:"vmVersion"`
+	Params           map[string]string    `json:"params"`
+	PrimaryMetric    jmhMetric            `json:"primaryMetric"`
+	SecondaryMetrics map[string]jmhMetric `json:"secondaryMetrics"`
+}
+
+func init() {
+	Register(Format{
+		Name:         "jmh",
+		Description:  "JMH -rf json results",
+		ContentTypes: []string{"application/json"},
+		Sniff: func(s *Sample) bool {
+			return s.Array && s.Keys["primaryMetric"]
+		},
+		Parse: parseJMH,
+	})
+}
+
+// parseJMH reads the file written by JMH with -rf json. Each measurement
+// iteration of every fork (rawData) is a sample; results without raw data,
+// such as sample-time mode histograms, contribute their score as a single
+// sample. The primary metric is named after the benchmark mode (thrpt,
+// avgt, sample, ss) and secondary metrics such as "gc.alloc.rate.norm"
+// after their profiler. Components are the benchmark method, suffixed with
+// its @Param values, e.g. "org.example.ParseBench.parse[size=10]".
+func parseJMH(r io.Reader) (*Result, error) {
+	var first *jmhResult
+	samples := newCollector()
+	dec := json.NewDecoder(r)
+
+	err := decodeArray(dec, func() error {
+		var result jmhResult
+		if err := decodeValue(dec, &result); err != nil {
+			return err
+		}
+		if result.Benchmark == "" {
+			return nil
+		}
+		if first == nil {
+			first = &result
+		}
+
+		component := jmhComponent(result)
+		addJMHMetric(samples, component, result.Mode, result.PrimaryMetric)
+
+		secondary := make([]string, 0, len(result.SecondaryMetrics))
+		for name := range result.SecondaryMetrics {
+			secondary = append(secondary, name)
+		}
+		sort.Strings(secondary)
+		for _, name := range secondary {
+			addJMHMetric(samples, component, strings.TrimPrefix(name, "·"), result.SecondaryMetrics[name])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	metadata := types.Metadata{}
+	for key, value := range map[string]string{
+		"jmh_version": first.JMHVersion,
+		"jdk_version": first.JDKVersion,
+		"vm_name":     first.VMName,
+		"vm_version":  first.VMVersion,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: metadata}, nil
+}
+
+func addJMHMetric(samples *collector, component, metric string, m jmhMetric) {
+	direction := jmhDirection(m.ScoreUnit)
+
+	found := false
+	for _, fork := range m.RawData {
+		for _, value := range fork {
+			samples.add(component, metric, m.ScoreUnit, direction, value)
+			found = true
+		}
+	}
+	if !found {
+		samples.add(component, metric, m.ScoreUnit, direction, m.Score)
+	}
+}
+
+func jmhComponent(result jmhResult) string {
+	if len(result.Params) == 0 {
+		return result.Benchmark
+	}
+
+	params := make([]string, 0, len(result.Params))
+	for key, value := range result.Params {
+		params = append(params, key+"="+value)
+	}
+	sort.Strings(params)
+
+	return result.Benchmark + "[" + strings.Join(params, ",") + "]"
+}
+
+// jmhDirection reads the direction off the score unit: throughput modes
+// report operations per time unit (ops/s, ops/ms), everything else time
+// or resources per operation.
+func jmhDirection(unit string) string {
+	if strings.HasPrefix(unit, "ops/") {
+		return types.DirectionHigher
+	}
+	return types.DirectionLower
+}
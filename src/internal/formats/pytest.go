@@ -0,0 +1,110 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"io"
+
+	"regression-ci/pkg/types"
+)
+
+// pytestMetric is the single series a pytest-benchmark test produces: the
+// wall time of one round, which pytest-benchmark reports in seconds.
+const (
+	pytestMetric = "time"
+	pytestUnit   = "s"
+)
+
+type pytestBenchmark struct {
+	Name     string `json:"name"`
+	Fullname string `json:"fullname"`
+	Stats    struct {
+		Mean   float64   `json:"mean"`
+		Rounds int       `json:"rounds"`
+		Data   []float64 `json:"data"`
+	} `json:"stats"`
+}
+
+// pytestMachineInfo lists the machine_info keys kept as run metadata.
+var pytestMachineInfo = []string{"python_implementation", "python_version", "system", "release", "machine", "node"}
+
+func init() {
+	Register(Format{
+		Name:         "pytest-benchmark",
+		Description:  "pytest-benchmark --benchmark-json report",
+		ContentTypes: []string{"application/json"},
+		Sniff: func(s *Sample) bool {
+			return !s.Array && s.Keys["machine_info"]
+		},
+		Parse: parsePytest,
+	})
+}
+
+// parsePytest reads the file written by pytest --benchmark-json. Per-round
+// timings are only included when pytest ran with --benchmark-save-data;
+// without them each test contributes its mean as a single sample.
+// Components are named by the test's node id, e.g.
+// "tests/test_parse.py::test_parse[small]".
+func parsePytest(r io.Reader) (*Result, error) {
+	var machineInfo map[string]interface{}
+	samples := newCollector()
+	dec := json.NewDecoder(r)
+
+	err := decodeObject(dec, map[string]func() error{
+		"machine_info": func() error { return decodeValue(dec, &machineInfo) },
+		"benchmarks": func() error {
+			return decodeArray(dec, func() error {
+				var bench pytestBenchmark
+				if err := decodeValue(dec, &bench); err != nil {
+					return err
+				}
+				addPytestBenchmark(samples, bench)
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples.measurements) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	metadata := types.Metadata{}
+	for _, key := range pytestMachineInfo {
+		if value, ok := machineInfo[key].(string); ok && value != "" {
+			metadata[key] = value
+		}
+	}
+	if cpu, ok := machineInfo["cpu"].(map[string]interface{}); ok {
+		if brand, ok := cpu["brand_raw"].(string); ok && brand != "" {
+			metadata["cpu"] = brand
+		}
+	}
+
+	return &Result{Measurements: samples.measurements, Metadata: metadata}, nil
+}
+
+func addPytestBenchmark(samples *collector, bench pytestBenchmark) {
+	component := bench.Fullname
+	if component == "" {
+		component = bench.Name
+	}
+	if component == "" {
+		return
+	}
+
+	values := bench.Stats.Data
+	if len(values) == 0 {
+		if bench.Stats.Rounds == 0 {
+			return
+		}
+		values = []float64{bench.Stats.Mean}
+	}
+	for _, value := range values {
+		samples.add(component, pytestMetric, pytestUnit, types.DirectionLower, value)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+
+// Package formats converts the output of benchmark tools into measurements.
+// Each tool is a Format registered from its own file; the server looks
+// formats up by name, by Content-Type, or by sniffing the uploaded body.
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"sort"
+	"sync"
+
+	"regression-ci/pkg/types"
+)
+
+// sniffSize is how much of an upload Sample.Head holds.
+const sniffSize = 512
+
+var (
+	ErrUnknownFormat = errors.New("unknown benchmark format")
+	ErrNoBenchmarks  = errors.New("no benchmark results found")
+	ErrMalformed     = errors.New("malformed benchmark output")
+)
+
+// Result is what a benchmark tool reported: one measurement per component
+// and metric with every repeated sample kept, plus whatever the tool said
+// about the environment it ran in.
+type Result struct {
+	Measurements []types.Measurement
+	Metadata     types.Metadata
+}
+
+// Format is one benchmark tool's output format.
+type Format struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ContentTypes are media types that can carry the format. A type that
+	// only one format claims selects it without sniffing.
+	ContentTypes []string `json:"content_types"`
+	// Sniff reports whether a sample of an upload is in this format. Sniffers
+	// must not overlap, as detection takes the first format that matches.
+	Sniff func(*Sample) bool               `json:"-"`
+	Parse func(io.Reader) (*Result, error) `json:"-"`
+}
+
+// Sample is what sniffers see of an upload: its first bytes and, when it is
+// JSON, the member names of the top-level object or of the first element
+// of a top-level array.
+type Sample struct {
+	Head  []byte
+	Keys  map[string]bool
+	Array bool
+}
+
+func (s *Sample) IsJSON() bool {
+	return s.Keys != nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Format)
+)
+
+// Register makes a format available. It panics on an incomplete format or
+// a duplicate name, since both are programming errors.
+func Register(f Format) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if f.Name == "" || f.Parse == nil || f.Sniff == nil {
+		panic("formats: Register of incomplete format " + f.Name)
+	}
+	if _, dup := registry[f.Name]; dup {
+		panic("formats: Register called twice for " + f.Name)
+	}
+	registry[f.Name] = f
+}
+
+func Lookup(name string) (Format, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	f, ok := registry[name]
+	return f, ok
+}
+
+// All returns the registered formats ordered by name.
+func All() []Format {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	all := make([]Format, 0, len(registry))
+	for _, f := range registry {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Parse reads benchmark output in the named format, or in whatever format
+// Detect recognises when name is empty.
+func Parse(name, contentType string, r io.Reader) (*Result, error) {
+	if name == "" {
+		detected, replay, err := Detect(contentType, r)
+		if err != nil {
+			return nil, err
+		}
+		defer replay.Close()
+		name, r = detected, replay
+	}
+
+	f, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+
+	return f.Parse(r)
+}
+
+// Detect works out the format of an upload, first from a Content-Type only
+// one format claims and otherwise by sniffing. The returned reader replays
+// the whole upload and must be closed: JSON uploads are spooled to a
+// temporary file while their keys are scanned, so memory stays bounded.
+func Detect(contentType string, r io.Reader) (string, io.ReadCloser, error) {
+	all := All()
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		var claimed []string
+		for _, f := range all {
+			for _, ct := range f.ContentTypes {
+				if ct == mediaType {
+					claimed = append(claimed, f.Name)
+				}
+			}
+		}
+		if len(claimed) == 1 {
+			return claimed[0], io.NopCloser(r), nil
+		}
+	}
+
+	sample, replay, err := sampleOf(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, f := range all {
+		if f.Sniff(sample) {
+			return f.Name, replay, nil
+		}
+	}
+
+	replay.Close()
+	return "", nil, fmt.Errorf("%w: could not detect the format of the upload", ErrUnknownFormat)
+}
+
+func sampleOf(r io.Reader) (*Sample, io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("failed to read benchmark output: %w", err)
+	}
+
+	sample := &Sample{Head: head}
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return sample, io.NopCloser(br), nil
+	}
+
+	file, err := os.CreateTemp("", "regression-ci-upload-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to spool upload: %w", err)
+	}
+	spooled := &spool{file}
+
+	if err := scanKeys(io.TeeReader(br, file), sample); err != nil {
+		spooled.Close()
+		return nil, nil, err
+	}
+	if _, err := io.Copy(file, br); err != nil {
+		spooled.Close()
+		return nil, nil, fmt.Errorf("failed to spool upload: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		spooled.Close()
+		return nil, nil, fmt.Errorf("failed to spool upload: %w", err)
+	}
+
+	return sample, spooled, nil
+}
+
+// scanKeys fills in the sample's member names without keeping any values.
+func scanKeys(r io.Reader, sample *Sample) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return malformed(err)
+	}
+
+	sample.Keys = make(map[string]bool)
+	if tok == json.Delim('[') {
+		sample.Array = true
+		if !dec.More() {
+			return nil
+		}
+		if tok, err = dec.Token(); err != nil {
+			return malformed(err)
+		}
+	}
+	if tok != json.Delim('{') {
+		return nil
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return malformed(err)
+		}
+		if key, ok := tok.(string); ok {
+			sample.Keys[key] = true
+		}
+		if err := skipValue(dec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// spool is an upload copied to a temporary file, removed once closed.
+type spool struct {
+	*os.File
+}
+
+func (s *spool) Close() error {
+	s.File.Close()
+	return os.Remove(s.Name())
+}
+
+// isArchive recognises gzip streams and ustar/GNU tar headers.
+func isArchive(head []byte) bool {
+	if len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b {
+		return true
+	}
+	return len(head) >= 262 && string(head[257:262]) == "ustar"
+}
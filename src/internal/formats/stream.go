@@ -0,0 +1,106 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package formats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The JSON formats are decoded member by member and element by element,
+// so a report with thousands of benchmarks is never held in memory whole.
+
+// decodeObject walks the members of a JSON object, handing each one with a
+// decoder in members to it. Other members are skipped.
+func decodeObject(dec *json.Decoder, members map[string]func() error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return malformed(err)
+		}
+		key, _ := tok.(string)
+		if decode, ok := members[key]; ok {
+			err = decode()
+		} else {
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeArray calls element once per element of a JSON array, with the
+// decoder positioned at it.
+func decodeArray(dec *json.Decoder, element func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := element(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// decodeValue decodes the next value, classifying the error the way the
+// stream helpers do.
+func decodeValue(dec *json.Decoder, v interface{}) error {
+	if err := dec.Decode(v); err != nil {
+		return malformed(err)
+	}
+	return nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return malformed(err)
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return malformed(err)
+	}
+	if tok != want {
+		return fmt.Errorf("%w: expected %v, found %v", ErrMalformed, want, tok)
+	}
+	return nil
+}
+
+// malformed blames the upload for JSON it cannot decode, but passes read
+// errors (such as an exceeded size limit) through untouched.
+func malformed(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	var syntax *json.SyntaxError
+	var mismatch *json.UnmarshalTypeError
+	if errors.As(err, &syntax) || errors.As(err, &mismatch) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return err
+}
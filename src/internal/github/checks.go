@@ -0,0 +1,69 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const (
+	CheckRunName       = "performance-regression"
+	ConfigCheckRunName = "performance-regression/config"
+)
+
+type CheckRunResult struct {
+	Name       string
+	HeadSHA    string
+	Conclusion string
+	Title      string
+	Summary    string
+}
+
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo string, result CheckRunResult) (int64, error) {
+	opts := github.CreateCheckRunOptions{
+		Name:       result.name(),
+		HeadSHA:    result.HeadSHA,
+		Status:     github.String("completed"),
+		Conclusion: github.String(result.Conclusion),
+		Output: &github.CheckRunOutput{
+			Title:   github.String(result.Title),
+			Summary: github.String(result.Summary),
+		},
+	}
+
+	run, _, err := c.client.Checks.CreateCheckRun(ctx, owner, repo, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	return run.GetID(), nil
+}
+
+func (c *Client) UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, result CheckRunResult) error {
+	opts := github.UpdateCheckRunOptions{
+		Name:       result.name(),
+		Status:     github.String("completed"),
+		Conclusion: github.String(result.Conclusion),
+		Output: &github.CheckRunOutput{
+			Title:   github.String(result.Title),
+			Summary: github.String(result.Summary),
+		},
+	}
+
+	if _, _, err := c.client.Checks.UpdateCheckRun(ctx, owner, repo, checkRunID, opts); err != nil {
+		return fmt.Errorf("failed to update check run: %w", err)
+	}
+
+	return nil
+}
+
+func (r CheckRunResult) name() string {
+	if r.Name == "" {
+		return CheckRunName
+	}
+	return r.Name
+}
@@ -5,7 +5,11 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
@@ -29,6 +33,12 @@ func New(cfg config.GitHubConfig) *Client {
 		client = github.NewClient(nil)
 	}
 
+	if cfg.APIURL != "" {
+		if enterprise, err := client.WithEnterpriseURLs(cfg.APIURL, cfg.APIURL); err == nil {
+			client = enterprise
+		}
+	}
+
 	return &Client{
 		client: client,
 		config: cfg,
@@ -79,6 +89,178 @@ func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*github
 	return repository, nil
 }
 
+func (c *Client) Enabled() bool {
+	return c.config.Token != ""
+}
+
+func (c *Client) FindPRComment(ctx context.Context, owner, repo string, prNumber int, marker string) (*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PR comments: %w", err)
+		}
+
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), marker) {
+				return comment, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (c *Client) UpsertPRComment(ctx context.Context, owner, repo string, prNumber int, marker, body string) error {
+	existing, err := c.FindPRComment(ctx, owner, repo, prNumber, marker)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return c.UpdatePRComment(ctx, owner, repo, existing.GetID(), body)
+	}
+	return c.CreatePRComment(ctx, owner, repo, prNumber, body)
+}
+
+// GetFileContents returns nil content without error when the file does not
+// exist at ref.
+func (c *Client) GetFileContents(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	file, _, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, opts)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return []byte(content), nil
+}
+
+// CompareCommits lists the commits after base up to and including head,
+// oldest first. It fails rather than return a partial range, since bisecting
+// over one would blame the wrong commit.
+func (c *Client) CompareCommits(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var shas []string
+	total := 0
+	for {
+		comparison, resp, err := c.client.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare commits: %w", err)
+		}
+
+		total = comparison.GetTotalCommits()
+		for _, commit := range comparison.Commits {
+			shas = append(shas, commit.GetSHA())
+		}
+		if resp.NextPage == 0 || len(shas) >= total {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(shas) != total {
+		return nil, fmt.Errorf("compare of %s...%s listed %d of %d commits", base, head, len(shas), total)
+	}
+
+	return shas, nil
+}
+
+func (c *Client) CreateCommitComment(ctx context.Context, owner, repo, sha, body string) error {
+	comment := &github.RepositoryComment{
+		Body: &body,
+	}
+
+	if _, _, err := c.client.Repositories.CreateComment(ctx, owner, repo, sha, comment); err != nil {
+		return fmt.Errorf("failed to create commit comment: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) AddLabel(ctx context.Context, owner, repo string, prNumber int, label string) error {
+	_, _, err := c.client.Issues.AddLabelsToIssue(ctx, owner, repo, prNumber, []string{label})
+	if err != nil {
+		return fmt.Errorf("failed to add label: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) RemoveLabel(ctx context.Context, owner, repo string, prNumber int, label string) error {
+	_, err := c.client.Issues.RemoveLabelForIssue(ctx, owner, repo, prNumber, label)
+	if err != nil {
+		return fmt.Errorf("failed to remove label: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	level, _, err := c.client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get permission level: %w", err)
+	}
+
+	return level.GetPermission(), nil
+}
+
+func (c *Client) CanWrite(ctx context.Context, owner, repo, user string) (bool, error) {
+	permission, err := c.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return false, err
+	}
+
+	switch permission {
+	case "admin", "maintain", "write":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (c *Client) Dispatch(ctx context.Context, owner, repo, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode dispatch payload: %w", err)
+	}
+
+	raw := json.RawMessage(data)
+	opts := github.DispatchRequestOptions{
+		EventType:     eventType,
+		ClientPayload: &raw,
+	}
+
+	if _, _, err := c.client.Repositories.Dispatch(ctx, owner, repo, opts); err != nil {
+		return fmt.Errorf("failed to send repository dispatch: %w", err)
+	}
+
+	return nil
+}
+
+func SplitRepo(fullName string) (string, string, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid repository name %q, expected owner/name", fullName)
+	}
+
+	return owner, name, nil
+}
+
 func (c *Client) ValidateWebhookSignature(payload []byte, signature string) bool {
 	if c.config.WebhookSecret == "" {
 		return false
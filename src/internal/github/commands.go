@@ -0,0 +1,64 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CommandAccept = "accept"
+	CommandIgnore = "ignore"
+	CommandRerun  = "rerun"
+
+	commandPrefix = "/perf"
+)
+
+type Command struct {
+	Name      string
+	Component string
+	Reason    string
+}
+
+// ParseCommands extracts every /perf command from a comment body, one per
+// line. Malformed commands are returned as errors so they can be reported
+// back to the commenter instead of being silently dropped.
+func ParseCommands(body string) ([]Command, []error) {
+	var commands []Command
+	var errs []error
+
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != commandPrefix {
+			continue
+		}
+
+		if len(fields) < 2 {
+			errs = append(errs, fmt.Errorf("missing command after %s", commandPrefix))
+			continue
+		}
+
+		cmd := Command{Name: strings.ToLower(fields[1])}
+		switch cmd.Name {
+		case CommandAccept:
+			if len(fields) < 3 {
+				errs = append(errs, fmt.Errorf("%s %s requires a component name", commandPrefix, CommandAccept))
+				continue
+			}
+			cmd.Component = fields[2]
+			cmd.Reason = strings.Join(fields[3:], " ")
+		case CommandIgnore:
+			cmd.Reason = strings.Join(fields[2:], " ")
+		case CommandRerun:
+		default:
+			errs = append(errs, fmt.Errorf("unknown command %s %s", commandPrefix, fields[1]))
+			continue
+		}
+
+		commands = append(commands, cmd)
+	}
+
+	return commands, errs
+}
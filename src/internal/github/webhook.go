@@ -0,0 +1,91 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package github
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+var ErrUnsupportedEvent = errors.New("unsupported webhook event")
+
+type PullRequestEvent struct {
+	Action   string
+	Repo     string
+	PRNumber int
+	HeadSHA  string
+	BaseRef  string
+	Label    string
+	Labels   []string
+}
+
+type PushEvent struct {
+	Repo          string
+	Ref           string
+	HeadSHA       string
+	Deleted       bool
+	DefaultBranch string
+}
+
+type CommentEvent struct {
+	Action   string
+	Repo     string
+	PRNumber int
+	Author   string
+	Body     string
+}
+
+func ParseEvent(eventType string, payload []byte) (interface{}, error) {
+	switch eventType {
+	case "issue_comment", "pull_request", "push":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, eventType)
+	}
+
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+	}
+
+	switch e := event.(type) {
+	case *github.PushEvent:
+		return &PushEvent{
+			Repo:          e.GetRepo().GetFullName(),
+			Ref:           e.GetRef(),
+			HeadSHA:       e.GetAfter(),
+			Deleted:       e.GetDeleted(),
+			DefaultBranch: e.GetRepo().GetDefaultBranch(),
+		}, nil
+	case *github.PullRequestEvent:
+		pr := e.GetPullRequest()
+		labels := make([]string, 0, len(pr.Labels))
+		for _, label := range pr.Labels {
+			labels = append(labels, label.GetName())
+		}
+		return &PullRequestEvent{
+			Action:   e.GetAction(),
+			Repo:     e.GetRepo().GetFullName(),
+			PRNumber: pr.GetNumber(),
+			HeadSHA:  pr.GetHead().GetSHA(),
+			BaseRef:  pr.GetBase().GetRef(),
+			Label:    e.GetLabel().GetName(),
+			Labels:   labels,
+		}, nil
+	case *github.IssueCommentEvent:
+		if !e.GetIssue().IsPullRequest() {
+			return nil, fmt.Errorf("%w: comment on plain issue", ErrUnsupportedEvent)
+		}
+		return &CommentEvent{
+			Action:   e.GetAction(),
+			Repo:     e.GetRepo().GetFullName(),
+			PRNumber: e.GetIssue().GetNumber(),
+			Author:   e.GetComment().GetUser().GetLogin(),
+			Body:     e.GetComment().GetBody(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, eventType)
+}
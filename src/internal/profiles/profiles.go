@@ -0,0 +1,150 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+
+	"regression-ci/pkg/types"
+)
+
+// TopFunctions is how many functions a diff keeps.
+const TopFunctions = 10
+
+var ErrInvalidProfile = errors.New("invalid pprof profile")
+
+// Parse reads a pprof profile, gzipped or not, as `go test -cpuprofile`
+// and `-memprofile` write them.
+func Parse(data []byte) (*profile.Profile, error) {
+	p, err := profile.ParseData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+	if len(p.SampleType) == 0 {
+		return nil, fmt.Errorf("%w: no sample types", ErrInvalidProfile)
+	}
+	return p, nil
+}
+
+// Kind names what a profile measures, so a run's CPU profile is only ever
+// compared with another CPU profile.
+func Kind(p *profile.Profile) string {
+	if p.PeriodType == nil || p.PeriodType.Type == "" {
+		return "profile"
+	}
+	switch p.PeriodType.Type {
+	case "space":
+		return "memory"
+	case "contentions":
+		return "contention"
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(p.PeriodType.Type))
+}
+
+// Diff compares the flat value of every function as a share of the whole
+// profile. Benchmarks run for however many iterations fill their time, so
+// absolute totals of two runs are not comparable but shares are. Functions
+// are ranked by how far their share moved.
+func Diff(base, current *profile.Profile) (*types.ProfileDiff, error) {
+	index := sampleIndex(current)
+	sampleType := current.SampleType[index]
+
+	baseIndex := -1
+	for i, st := range base.SampleType {
+		if st.Type == sampleType.Type {
+			baseIndex = i
+		}
+	}
+	if baseIndex < 0 {
+		return nil, fmt.Errorf("baseline profile has no %s samples", sampleType.Type)
+	}
+
+	baseFlat, baseTotal := flat(base, baseIndex)
+	currentFlat, currentTotal := flat(current, index)
+	if baseTotal == 0 || currentTotal == 0 {
+		return nil, fmt.Errorf("profile has no %s samples", sampleType.Type)
+	}
+
+	functions := make([]types.FunctionDelta, 0, len(currentFlat))
+	seen := make(map[string]bool)
+	for _, values := range []map[string]float64{currentFlat, baseFlat} {
+		for name := range values {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			delta := types.FunctionDelta{
+				Function: name,
+				Baseline: baseFlat[name] / baseTotal * 100,
+				Current:  currentFlat[name] / currentTotal * 100,
+			}
+			delta.Delta = delta.Current - delta.Baseline
+			functions = append(functions, delta)
+		}
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		a, b := math.Abs(functions[i].Delta), math.Abs(functions[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return functions[i].Function < functions[j].Function
+	})
+	if len(functions) > TopFunctions {
+		functions = functions[:TopFunctions]
+	}
+
+	return &types.ProfileDiff{
+		SampleType:    sampleType.Type,
+		Unit:          sampleType.Unit,
+		BaselineTotal: baseTotal,
+		CurrentTotal:  currentTotal,
+		Functions:     functions,
+	}, nil
+}
+
+// sampleIndex follows pprof: the declared default sample type, else the
+// last one.
+func sampleIndex(p *profile.Profile) int {
+	for i, st := range p.SampleType {
+		if st.Type == p.DefaultSampleType {
+			return i
+		}
+	}
+	return len(p.SampleType) - 1
+}
+
+// flat attributes every sample to the function it was taken in. Inlined
+// frames share a location whose first line is the innermost function.
+func flat(p *profile.Profile, index int) (map[string]float64, float64) {
+	values := make(map[string]float64)
+	var total float64
+	for _, sample := range p.Sample {
+		if len(sample.Location) == 0 || index >= len(sample.Value) {
+			continue
+		}
+		value := float64(sample.Value[index])
+		total += value
+		values[functionName(sample.Location[0])] += value
+	}
+	return values, total
+}
+
+func functionName(location *profile.Location) string {
+	if len(location.Line) > 0 && location.Line[0].Function != nil {
+		return location.Line[0].Function.Name
+	}
+	return fmt.Sprintf("0x%x", location.Address)
+}
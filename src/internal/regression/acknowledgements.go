@@ -0,0 +1,89 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"regression-ci/pkg/types"
+)
+
+const (
+	AckActionAccept = "accept"
+	AckActionIgnore = "ignore"
+
+	AllComponents = "*"
+)
+
+func (d *Detector) Acknowledge(ack types.Acknowledgement) error {
+	if ack.CreatedAt == 0 {
+		ack.CreatedAt = time.Now().Unix()
+	}
+
+	return d.store.AddAcknowledgement(ack)
+}
+
+func (d *Detector) Acknowledgements(repo string, prNumber int) ([]types.Acknowledgement, error) {
+	return d.store.Acknowledgements(repo, prNumber)
+}
+
+// ApplyAcknowledgements attaches the most recent matching acknowledgement to
+// every regressed component; a PR-wide ignore matches all of them. Only
+// acknowledgements made against the analysed commit count, so a later push
+// has to be accepted again.
+func ApplyAcknowledgements(resp *types.AnalyzeResponse, acks []types.Acknowledgement) {
+	for i := range resp.Components {
+		component := &resp.Components[i]
+		component.Acknowledgement = nil
+		if component.Result == nil || !component.Result.IsRegression {
+			continue
+		}
+
+		for j := len(acks) - 1; j >= 0; j-- {
+			if acks[j].HeadSHA != resp.Commit {
+				continue
+			}
+			if acks[j].Component == component.Component || acks[j].Component == AllComponents {
+				ack := acks[j]
+				component.Acknowledgement = &ack
+				break
+			}
+		}
+	}
+}
+
+func (d *Detector) SavePRReport(resp *types.AnalyzeResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return d.store.SavePRReport(types.PRReport{
+		Repo:      resp.Repo,
+		PRNumber:  resp.PRNumber,
+		HeadSHA:   resp.Commit,
+		Response:  string(data),
+		UpdatedAt: time.Now().Unix(),
+	})
+}
+
+func (d *Detector) PRReport(repo string, prNumber int) (*types.PRReport, *types.AnalyzeResponse, error) {
+	report, err := d.store.PRReport(repo, prNumber)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PR report not found: %w", err)
+	}
+
+	var resp types.AnalyzeResponse
+	if err := json.Unmarshal([]byte(report.Response), &resp); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode PR report: %w", err)
+	}
+
+	return report, &resp, nil
+}
+
+func (d *Detector) SetCheckRunID(repo string, prNumber int, checkRunID int64) error {
+	return d.store.SetCheckRunID(repo, prNumber, checkRunID)
+}
@@ -4,96 +4,212 @@
 package regression
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"gonum.org/v1/gonum/stat"
 
+	"regression-ci/internal/storage"
 	"regression-ci/pkg/types"
 )
 
-func (d *Detector) detectRegression(repo, component string, currentValue float64) (*types.RegressionResult, error) {
-	baseline, err := d.getBaseline(repo, component)
+const (
+	ThresholdModeRelative = "relative"
+	ThresholdModeAbsolute = "absolute"
+)
+
+func (d *Detector) detectRegression(repo string, m types.Measurement, cfg analysisConfig) (*types.RegressionResult, error) {
+	currentValue := stat.Mean(m.Samples, nil)
+	baseline, err := d.store.Baseline(repo, m.Component, m.Metric)
 	if err != nil {
-		return d.createInitialBaseline(repo, component, currentValue)
+		return d.createInitialBaseline(repo, m, currentValue, cfg)
 	}
 
-	percentChange := ((currentValue - baseline.BaselineValue) / baseline.BaselineValue) * 100
-	
-	threshold := d.config.DefaultThreshold
-	repoConfig, err := d.getRepoConfig(repo)
-	if err == nil && repoConfig.ThresholdPercent > 0 {
-		threshold = repoConfig.ThresholdPercent
+	return d.evaluate(repo, m, baseline, cfg), nil
+}
+
+// measure evaluates a run against the existing baseline only, for runs that
+// must not shape baselines: reanalysis and bisection midpoints.
+func (d *Detector) measure(repo string, m types.Measurement, cfg analysisConfig) (*types.RegressionResult, error) {
+	baseline, err := d.store.Baseline(repo, m.Component, m.Metric)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrBaselineNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	isRegression := percentChange > threshold
-	confidence := d.calculateConfidence(baseline, currentValue, percentChange)
+	return d.evaluate(repo, m, baseline, cfg), nil
+}
+
+// evaluate measures a run against an existing baseline without touching it.
+func (d *Detector) evaluate(repo string, m types.Measurement, baseline *types.Baseline, cfg analysisConfig) *types.RegressionResult {
+	currentValue := stat.Mean(m.Samples, nil)
+	threshold, absoluteThreshold, _ := cfg.component(m.Component)
+	result, magnitude := d.compare(baseline.BaselineValue, currentValue, threshold, absoluteThreshold)
+	result.ConfidenceScore = d.calculateConfidence(baseline, magnitude, cfg.minSamples)
+	result.SampleSize = baseline.SampleCount
+	result.PValue = d.significance(repo, m)
+	if result.PValue != nil {
+		result.ConfidenceScore = (1 - *result.PValue) * 100
+	}
+	result.IsRegression = isRegression(result, m.Direction, cfg.significanceLevel)
+	if source, err := d.store.BaselineSource(repo, m.Component, m.Metric); err == nil {
+		result.BaselineSource = source.Source
+		result.BaselineCommit = source.PinnedCommit
+	}
+
+	return result
+}
+
+// compare measures a current value against a baseline value. The magnitude
+// it returns is the change scaled to the relative threshold, for confidence.
+func (d *Detector) compare(baselineValue, currentValue, threshold, absoluteThreshold float64) (*types.RegressionResult, float64) {
+	absoluteChange := currentValue - baselineValue
+	percentChange := 0.0
+	mode := thresholdMode(baselineValue, threshold, absoluteThreshold)
+	var magnitude float64
+	appliedThreshold := threshold
+
+	if mode == ThresholdModeAbsolute {
+		appliedThreshold = absoluteThreshold
+		if absoluteThreshold > 0 {
+			magnitude = math.Abs(absoluteChange) / absoluteThreshold * threshold
+		}
+	} else {
+		percentChange = (absoluteChange / baselineValue) * 100
+		magnitude = math.Abs(percentChange)
+	}
 
 	return &types.RegressionResult{
-		IsRegression:    isRegression,
-		CurrentValue:    currentValue,
-		BaselineValue:   baseline.BaselineValue,
-		PercentChange:   percentChange,
-		ConfidenceScore: confidence,
-		SampleSize:      baseline.SampleCount,
-	}, nil
+		CurrentValue:   currentValue,
+		BaselineValue:  baselineValue,
+		PercentChange:  percentChange,
+		AbsoluteChange: absoluteChange,
+		ThresholdMode:  mode,
+		Threshold:      appliedThreshold,
+	}, magnitude
+}
+
+// significance compares the run's samples with the samples behind the
+// baseline. Single-value uploads have no spread to test, so they get none.
+func (d *Detector) significance(repo string, m types.Measurement) *float64 {
+	samples, err := d.store.BaselineSamples(repo, m.Component, m.Metric)
+	if err != nil {
+		return nil
+	}
+
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+
+	p, ok := welchTTest(m.Samples, values)
+	if !ok {
+		return nil
+	}
+	return &p
 }
 
-func (d *Detector) createInitialBaseline(repo, component string, value float64) (*types.RegressionResult, error) {
-	baseline := &types.Baseline{
+// isRegression reports whether a result moved the wrong way by more than its
+// threshold and, when it carries a p-value, did so significantly at alpha.
+func isRegression(result *types.RegressionResult, direction string, alpha float64) bool {
+	change := result.PercentChange
+	if result.ThresholdMode == ThresholdModeAbsolute {
+		change = result.AbsoluteChange
+	}
+	if direction == types.DirectionHigher {
+		change = -change
+	}
+	if change <= result.Threshold {
+		return false
+	}
+
+	return result.PValue == nil || alpha <= 0 || *result.PValue < alpha
+}
+
+// thresholdMode judges a baseline near zero, in its own units, when the
+// relative threshold would allow less change than the absolute one. Below that
+// point percentages of a tiny baseline only magnify noise.
+func thresholdMode(baselineValue, threshold, absoluteThreshold float64) string {
+	if baselineValue == 0 || math.Abs(baselineValue)*threshold/100 < absoluteThreshold {
+		return ThresholdModeAbsolute
+	}
+	return ThresholdModeRelative
+}
+
+func (d *Detector) createInitialBaseline(repo string, m types.Measurement, value float64, cfg analysisConfig) (*types.RegressionResult, error) {
+	threshold, absoluteThreshold, _ := cfg.component(m.Component)
+	baseline := types.Baseline{
 		Repo:          repo,
-		Component:     component,
+		Component:     m.Component,
+		Metric:        m.Metric,
 		BaselineValue: value,
-		SampleCount:   1,
+		SampleCount:   len(m.Samples),
 		UpdatedAt:     time.Now().Unix(),
 	}
 
-	query := `INSERT OR REPLACE INTO baselines 
-	          (repo, component, baseline_value, sample_count, updated_at) 
-	          VALUES (?, ?, ?, ?, ?)`
-	
-	_, err := d.db.Exec(query, baseline.Repo, baseline.Component, 
-		baseline.BaselineValue, baseline.SampleCount, baseline.UpdatedAt)
+	samples, err := d.store.RecentBenchmarks(repo, m.Component, m.Metric, len(m.Samples))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create baseline: %w", err)
 	}
+	if err := d.saveBaseline(baseline, BaselineSourceInitial, "", samples); err != nil {
+		return nil, fmt.Errorf("failed to create baseline: %w", err)
+	}
 
 	return &types.RegressionResult{
 		IsRegression:    false,
 		CurrentValue:    value,
 		BaselineValue:   value,
 		PercentChange:   0.0,
+		ThresholdMode:   thresholdMode(value, threshold, absoluteThreshold),
 		ConfidenceScore: 100.0,
-		SampleSize:      1,
+		SampleSize:      len(m.Samples),
+		BaselineSource:  BaselineSourceInitial,
 	}, nil
 }
 
-func (d *Detector) updateBaseline(repo, component string, newValue float64, result *types.RegressionResult) {
+func (d *Detector) updateBaseline(repo string, m types.Measurement, result *types.RegressionResult, cfg analysisConfig) {
 	if result.IsRegression {
 		return
 	}
 
-	recentSamples, err := d.getRecentSamples(repo, component, d.config.MaxSamples)
-	if err != nil || len(recentSamples) < d.config.MinSamples {
+	if source, err := d.store.BaselineSource(repo, m.Component, m.Metric); err == nil && source.Source == BaselineSourcePinned {
 		return
 	}
 
-	newBaseline := stat.Mean(recentSamples, nil)
-	
-	query := `UPDATE baselines SET baseline_value = ?, sample_count = ?, updated_at = ? 
-	          WHERE repo = ? AND component = ?`
-	
-	d.db.Exec(query, newBaseline, len(recentSamples), time.Now().Unix(), repo, component)
+	recentSamples, err := d.store.RecentBenchmarks(repo, m.Component, m.Metric, cfg.maxSamples)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repo).Str("component", m.Component).Msg("failed to load samples for baseline update")
+		return
+	}
+	if len(recentSamples) < cfg.minSamples {
+		return
+	}
+
+	baseline := types.Baseline{
+		Repo:          repo,
+		Component:     m.Component,
+		Metric:        m.Metric,
+		BaselineValue: estimateBaseline(recentSamples),
+		SampleCount:   len(recentSamples),
+		UpdatedAt:     time.Now().Unix(),
+	}
+
+	if err := d.saveBaseline(baseline, BaselineSourceRolling, "", recentSamples); err != nil {
+		log.Warn().Err(err).Str("repo", repo).Str("component", m.Component).Msg("failed to update baseline")
+	}
 }
 
-func (d *Detector) calculateConfidence(baseline *types.Baseline, currentValue, percentChange float64) float64 {
-	if baseline.SampleCount < d.config.MinSamples {
+func (d *Detector) calculateConfidence(baseline *types.Baseline, magnitude float64, minSamples int) float64 {
+	if baseline.SampleCount < minSamples {
 		return 50.0
 	}
 
-	changeAbs := math.Abs(percentChange)
-	confidence := math.Min(90.0, 50.0+(changeAbs*2))
+	confidence := math.Min(90.0, 50.0+(magnitude*2))
 	
 	return confidence
 }
\ No newline at end of file
@@ -0,0 +1,219 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+const (
+	BaselineSourceInitial = "initial"
+	BaselineSourceRolling = "rolling"
+	BaselineSourcePinned  = "pinned"
+	BaselineSourceRebuilt = "rebuilt"
+
+	estimatorName = "mean"
+)
+
+var (
+	ErrBaselineNotFound = errors.New("baseline not found")
+	ErrNoSamples        = errors.New("no samples found")
+)
+
+func estimateBaseline(samples []types.Benchmark) float64 {
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+	return stat.Mean(values, nil)
+}
+
+func (d *Detector) PinBaseline(repo, component, metric, commit string) (*types.BaselineProvenance, error) {
+	samples, err := d.store.CommitBenchmarks(repo, component, metric, commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit samples: %w", err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w for %s at commit %s", ErrNoSamples, component, commit)
+	}
+
+	baseline := types.Baseline{
+		Repo:          repo,
+		Component:     component,
+		Metric:        metric,
+		BaselineValue: estimateBaseline(samples),
+		SampleCount:   len(samples),
+		UpdatedAt:     time.Now().Unix(),
+	}
+
+	if err := d.saveBaseline(baseline, BaselineSourcePinned, commit, samples); err != nil {
+		return nil, err
+	}
+
+	return d.BaselineProvenance(repo, component, metric)
+}
+
+func (d *Detector) ResetBaseline(repo, component, metric string) error {
+	err := d.store.DeleteBaseline(repo, component, metric)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrBaselineNotFound
+	}
+	return err
+}
+
+// RebuildBaselines re-estimates every baseline of a repository from recent
+// samples. Pinned baselines are kept and returned separately unless force
+// is set, in which case they are rebuilt like any other.
+func (d *Detector) RebuildBaselines(repo string, force bool) ([]types.Baseline, []types.Baseline, error) {
+	series, err := d.store.BenchmarkSeries(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxSamples := d.detection().MaxSamples
+	baselines := make([]types.Baseline, 0, len(series))
+	pinned := []types.Baseline{}
+	for _, s := range series {
+		if !force {
+			source, err := d.store.BaselineSource(repo, s.Component, s.Metric)
+			if err == nil && source.Source == BaselineSourcePinned {
+				if baseline, err := d.store.Baseline(repo, s.Component, s.Metric); err == nil {
+					pinned = append(pinned, *baseline)
+					continue
+				}
+			}
+		}
+
+		baseline, err := d.rebuildBaseline(repo, s, maxSamples)
+		if err != nil {
+			return nil, nil, err
+		}
+		if baseline != nil {
+			baselines = append(baselines, *baseline)
+		}
+	}
+
+	return baselines, pinned, nil
+}
+
+// rebuildBaseline re-estimates a series' baseline from its most recent
+// samples. It returns nil when the series has none left.
+func (d *Detector) rebuildBaseline(repo string, s storage.Series, maxSamples int) (*types.Baseline, error) {
+	samples, err := d.store.RecentBenchmarks(repo, s.Component, s.Metric, maxSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load samples for %s %s: %w", s.Component, s.Metric, err)
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	baseline := types.Baseline{
+		Repo:          repo,
+		Component:     s.Component,
+		Metric:        s.Metric,
+		BaselineValue: estimateBaseline(samples),
+		SampleCount:   len(samples),
+		UpdatedAt:     time.Now().Unix(),
+	}
+
+	if err := d.saveBaseline(baseline, BaselineSourceRebuilt, "", samples); err != nil {
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// refreshBaseline rebuilds a series' baseline after samples were removed,
+// or drops it when none are left. Pinned baselines are left alone.
+func (d *Detector) refreshBaseline(repo string, s storage.Series, maxSamples int) (*types.Baseline, error) {
+	if _, err := d.store.Baseline(repo, s.Component, s.Metric); errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	source, err := d.store.BaselineSource(repo, s.Component, s.Metric)
+	if err == nil && source.Source == BaselineSourcePinned {
+		return nil, nil
+	}
+
+	baseline, err := d.rebuildBaseline(repo, s, maxSamples)
+	if err != nil || baseline != nil {
+		return baseline, err
+	}
+
+	err = d.store.DeleteBaseline(repo, s.Component, s.Metric)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to delete baseline: %w", err)
+	}
+	return nil, nil
+}
+
+// Series lists a repository's series with their baselines.
+func (d *Detector) Series(repo string) ([]types.SeriesSummary, error) {
+	series, err := d.store.SeriesSummaries(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range series {
+		if series[i].BaselineValue != nil && series[i].BaselineSource == "" {
+			series[i].BaselineSource = BaselineSourceInitial
+		}
+	}
+	return series, nil
+}
+
+func (d *Detector) BaselineProvenance(repo, component, metric string) (*types.BaselineProvenance, error) {
+	baseline, err := d.store.Baseline(repo, component, metric)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrBaselineNotFound
+		}
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+
+	provenance := &types.BaselineProvenance{
+		Baseline:  *baseline,
+		Source:    BaselineSourceInitial,
+		Estimator: estimatorName,
+		Commits:   []string{},
+	}
+
+	source, err := d.store.BaselineSource(repo, component, metric)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load baseline source: %w", err)
+	}
+	if err == nil {
+		provenance.Source = source.Source
+		provenance.Estimator = source.Estimator
+		provenance.PinnedCommit = source.PinnedCommit
+	}
+
+	provenance.Samples, err = d.store.BaselineSamples(repo, component, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, sample := range provenance.Samples {
+		if !seen[sample.CommitHash] {
+			seen[sample.CommitHash] = true
+			provenance.Commits = append(provenance.Commits, sample.CommitHash)
+		}
+	}
+
+	return provenance, nil
+}
+
+func (d *Detector) saveBaseline(baseline types.Baseline, source, pinnedCommit string, samples []types.Benchmark) error {
+	return d.store.SaveBaseline(baseline, storage.BaselineSource{
+		Source:       source,
+		Estimator:    estimatorName,
+		PinnedCommit: pinnedCommit,
+	}, samples)
+}
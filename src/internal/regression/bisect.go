@@ -0,0 +1,78 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"regression-ci/pkg/types"
+)
+
+const (
+	BisectionRunning    = "running"
+	BisectionCandidates = "candidates"
+	BisectionComplete   = "complete"
+)
+
+// Candidates are ordered oldest to newest and exclude the good commit, so the
+// last candidate is always the known-bad commit. The culprit lies within
+// Candidates[Low..High].
+func NextBisectionCommit(b *types.Bisection) string {
+	if b.Low >= b.High {
+		return ""
+	}
+	return b.Candidates[(b.Low+b.High)/2]
+}
+
+func RecordBisectionSample(b *types.Bisection, value float64) {
+	mid := (b.Low + b.High) / 2
+	if math.Abs(value-b.BadValue) < math.Abs(value-b.GoodValue) {
+		b.High = mid
+	} else {
+		b.Low = mid + 1
+	}
+
+	if b.Low >= b.High {
+		b.Status = BisectionComplete
+		b.Culprit = b.Candidates[b.Low]
+		b.PendingCommit = ""
+	}
+}
+
+func (d *Detector) LastSampleBefore(repo, branch, component, metric, commit string) (*types.Benchmark, error) {
+	sample, err := d.store.LastBenchmarkBefore(repo, branch, component, metric, commit)
+	if err != nil {
+		return nil, fmt.Errorf("no earlier sample: %w", err)
+	}
+
+	return sample, nil
+}
+
+func (d *Detector) CreateBisection(b *types.Bisection) error {
+	now := time.Now().Unix()
+	b.CreatedAt = now
+	b.UpdatedAt = now
+
+	return d.store.CreateBisection(b)
+}
+
+func (d *Detector) UpdateBisection(b *types.Bisection) error {
+	b.UpdatedAt = time.Now().Unix()
+
+	return d.store.UpdateBisection(b)
+}
+
+func (d *Detector) PendingBisections(repo, commit string) ([]types.Bisection, error) {
+	return d.store.PendingBisections(repo, BisectionRunning, commit)
+}
+
+func (d *Detector) HasBisection(repo, badCommit string) (bool, error) {
+	return d.store.HasBisection(repo, badCommit)
+}
+
+func (d *Detector) Bisections(repo string) ([]types.Bisection, error) {
+	return d.store.Bisections(repo)
+}
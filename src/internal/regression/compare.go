@@ -0,0 +1,128 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"gonum.org/v1/gonum/stat"
+
+	"regression-ci/internal/config"
+	"regression-ci/pkg/types"
+)
+
+const (
+	VerdictRegression  = "regression"
+	VerdictImprovement = "improvement"
+	VerdictUnchanged   = "unchanged"
+	VerdictAdded       = "added"
+	VerdictRemoved     = "removed"
+)
+
+// Comparison is one metric of two result sets. Result is nil when the metric
+// is only in one of them.
+type Comparison struct {
+	Component  string                  `json:"component"`
+	Metric     string                  `json:"metric"`
+	Unit       string                  `json:"unit,omitempty"`
+	Direction  string                  `json:"direction"`
+	OldSamples int                     `json:"old_samples"`
+	NewSamples int                     `json:"new_samples"`
+	Verdict    string                  `json:"verdict"`
+	Result     *types.RegressionResult `json:"result,omitempty"`
+}
+
+// Compare runs the server's analysis on two result sets without a store:
+// before stands in for the baseline and its samples for the baseline samples.
+// A repository file, when given, applies as it would on the server.
+func Compare(detection config.DetectionConfig, file *config.RepoFile, before, after []types.Measurement) []Comparison {
+	d := &Detector{}
+	cfg := defaultConfig(detection)
+	if file != nil {
+		cfg.apply(file)
+	}
+	if !cfg.enabled {
+		return []Comparison{}
+	}
+
+	before = types.AnalyzeRequest{Measurements: before}.AllMeasurements()
+	after = types.AnalyzeRequest{Measurements: after}.AllMeasurements()
+	key := func(m types.Measurement) string { return m.Component + "\x00" + m.Metric }
+	baselines := make(map[string]types.Measurement, len(before))
+	for _, m := range before {
+		baselines[key(m)] = m
+	}
+
+	comparisons := make([]Comparison, 0, len(after))
+	matched := make(map[string]bool, len(after))
+	for _, m := range after {
+		threshold, absoluteThreshold, enabled := cfg.component(m.Component)
+		if !enabled || len(m.Samples) == 0 {
+			continue
+		}
+
+		comparison := Comparison{
+			Component:  m.Component,
+			Metric:     m.Metric,
+			Unit:       m.Unit,
+			Direction:  m.Direction,
+			NewSamples: len(m.Samples),
+			Verdict:    VerdictAdded,
+		}
+		base, ok := baselines[key(m)]
+		if ok && len(base.Samples) > 0 {
+			matched[key(m)] = true
+			comparison.OldSamples = len(base.Samples)
+			comparison.Result = d.compareSamples(base.Samples, m.Samples, threshold, absoluteThreshold, cfg.minSamples)
+			comparison.Verdict = verdict(comparison.Result, m.Direction, cfg.significanceLevel)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	for _, m := range before {
+		if _, _, enabled := cfg.component(m.Component); !enabled || matched[key(m)] || len(m.Samples) == 0 {
+			continue
+		}
+		comparisons = append(comparisons, Comparison{
+			Component:  m.Component,
+			Metric:     m.Metric,
+			Unit:       m.Unit,
+			Direction:  m.Direction,
+			OldSamples: len(m.Samples),
+			Verdict:    VerdictRemoved,
+		})
+	}
+
+	return comparisons
+}
+
+// compareSamples is detectRegression with the before samples as the baseline.
+func (d *Detector) compareSamples(before, after []float64, threshold, absoluteThreshold float64, minSamples int) *types.RegressionResult {
+	baseline := &types.Baseline{BaselineValue: stat.Mean(before, nil), SampleCount: len(before)}
+	result, magnitude := d.compare(baseline.BaselineValue, stat.Mean(after, nil), threshold, absoluteThreshold)
+	result.ConfidenceScore = d.calculateConfidence(baseline, magnitude, minSamples)
+	result.SampleSize = baseline.SampleCount
+	if p, ok := welchTTest(after, before); ok {
+		result.PValue = &p
+		result.ConfidenceScore = (1 - p) * 100
+	}
+	return result
+}
+
+// verdict sets IsRegression and calls a change that would be a regression
+// in the opposite direction an improvement.
+func verdict(result *types.RegressionResult, direction string, alpha float64) string {
+	result.IsRegression = isRegression(result, direction, alpha)
+	opposite := types.DirectionHigher
+	if direction == types.DirectionHigher {
+		opposite = types.DirectionLower
+	}
+
+	switch {
+	case result.IsRegression:
+		return VerdictRegression
+	case isRegression(result, opposite, alpha):
+		return VerdictImprovement
+	default:
+		return VerdictUnchanged
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/config"
+)
+
+// analysisConfig is the effective configuration for one analysis: service
+// defaults, overridden by the config table, overridden by the repository's
+// own .regression-ci.yml at the analysed commit. It is resolved once per
+// call so a config reload cannot mix settings within one analysis.
+type analysisConfig struct {
+	threshold         float64
+	absoluteThreshold float64
+	minSamples        int
+	maxSamples        int
+	significanceLevel float64
+	enabled           bool
+	components        map[string]config.RepoFileComponent
+}
+
+func (d *Detector) resolveConfig(repo, branch, commit string) analysisConfig {
+	cfg := defaultConfig(d.detection())
+
+	if repoConfig, err := d.store.RepoConfig(repo); err == nil {
+		if repoConfig.ThresholdPercent > 0 {
+			cfg.threshold = repoConfig.ThresholdPercent
+		}
+		if repoConfig.MinSamples > 0 {
+			cfg.minSamples = repoConfig.MinSamples
+		}
+		cfg.enabled = repoConfig.Enabled
+	}
+
+	file, err := d.RepoFile(repo, branch, commit)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repo).Str("commit", commit).Msg("ignoring repository config")
+	}
+	if file != nil {
+		cfg.apply(file)
+	}
+
+	return cfg
+}
+
+func defaultConfig(defaults config.DetectionConfig) analysisConfig {
+	return analysisConfig{
+		threshold:         defaults.DefaultThreshold,
+		absoluteThreshold: defaults.AbsoluteThreshold,
+		minSamples:        defaults.MinSamples,
+		maxSamples:        defaults.MaxSamples,
+		significanceLevel: defaults.SignificanceLevel,
+		enabled:           true,
+	}
+}
+
+func (c *analysisConfig) apply(file *config.RepoFile) {
+	if file.Enabled != nil {
+		c.enabled = *file.Enabled
+	}
+	if file.ThresholdPercent != nil {
+		c.threshold = *file.ThresholdPercent
+	}
+	if file.AbsoluteThreshold != nil {
+		c.absoluteThreshold = *file.AbsoluteThreshold
+	}
+	if file.MinSamples != nil {
+		c.minSamples = *file.MinSamples
+	}
+	c.components = file.Components
+}
+
+func (c analysisConfig) component(name string) (threshold, absoluteThreshold float64, enabled bool) {
+	threshold, absoluteThreshold, enabled = c.threshold, c.absoluteThreshold, true
+
+	override, ok := c.components[name]
+	if !ok {
+		return threshold, absoluteThreshold, enabled
+	}
+
+	if override.Enabled != nil {
+		enabled = *override.Enabled
+	}
+	if override.ThresholdPercent != nil {
+		threshold = *override.ThresholdPercent
+	}
+	if override.AbsoluteThreshold != nil {
+		absoluteThreshold = *override.AbsoluteThreshold
+	}
+
+	return threshold, absoluteThreshold, enabled
+}
@@ -5,24 +5,34 @@ package regression
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
-	"github.com/jmoiron/sqlx"
-
 	"regression-ci/internal/config"
+	"regression-ci/internal/storage"
 	"regression-ci/pkg/types"
 )
 
 type Detector struct {
-	db     *sqlx.DB
-	config config.DetectionConfig
+	store  storage.Store
+	config atomic.Pointer[config.DetectionConfig]
 }
 
-func New(db *sqlx.DB, cfg config.DetectionConfig) *Detector {
-	return &Detector{
-		db:     db,
-		config: cfg,
+func New(store storage.Store, cfg config.DetectionConfig) *Detector {
+	d := &Detector{
+		store: store,
 	}
+	d.SetConfig(cfg)
+
+	return d
+}
+
+func (d *Detector) SetConfig(cfg config.DetectionConfig) {
+	d.config.Store(&cfg)
+}
+
+func (d *Detector) detection() config.DetectionConfig {
+	return *d.config.Load()
 }
 
 func (d *Detector) Analyze(req types.AnalyzeRequest) (*types.AnalyzeResponse, error) {
@@ -30,49 +40,97 @@ func (d *Detector) Analyze(req types.AnalyzeRequest) (*types.AnalyzeResponse, er
 	response := &types.AnalyzeResponse{
 		Repo:      req.Repo,
 		Commit:    req.Commit,
+		PRNumber:  req.PRNumber,
 		Timestamp: timestamp,
 	}
 
-	if err := d.storeBenchmarks(req, timestamp); err != nil {
+	runID, err := d.storeRun(req, timestamp)
+	if err != nil {
 		return nil, fmt.Errorf("failed to store benchmarks: %w", err)
 	}
+	response.RunID = runID
+
+	cfg := d.resolveConfig(req.Repo, req.Branch, req.Commit)
+	if !cfg.enabled {
+		response.Components = []types.ComponentResult{}
+		d.saveAnalysis(response)
+		return response, nil
+	}
+
+	for _, m := range req.AllMeasurements() {
+		if _, _, enabled := cfg.component(m.Component); !enabled {
+			continue
+		}
 
-	for component, value := range req.Components {
-		result, err := d.detectRegression(req.Repo, component, value)
+		var result *types.RegressionResult
+		var err error
+		if req.BisectionID != 0 {
+			result, err = d.measure(req.Repo, m, cfg)
+		} else {
+			result, err = d.detectRegression(req.Repo, m, cfg)
+		}
 		
 		componentResult := types.ComponentResult{
-			Component: component,
+			Component: m.Component,
+			Metric:    m.Metric,
+			Unit:      m.Unit,
+			Direction: m.Direction,
 		}
 		
 		if err != nil {
 			componentResult.Error = err.Error()
 		} else {
 			componentResult.Result = result
-			d.updateBaseline(req.Repo, component, value, result)
+			if req.BisectionID == 0 {
+				d.updateBaseline(req.Repo, m, result, cfg)
+			}
 		}
 		
 		response.Components = append(response.Components, componentResult)
 	}
 
+	d.saveAnalysis(response)
 	return response, nil
 }
 
-func (d *Detector) storeBenchmarks(req types.AnalyzeRequest, timestamp int64) error {
-	tx, err := d.db.Beginx()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+func (d *Detector) storeRun(req types.AnalyzeRequest, timestamp int64) (int64, error) {
+	run := &types.Run{
+		Repo:        req.Repo,
+		Branch:      req.Branch,
+		CommitHash:  req.Commit,
+		PRNumber:    req.PRNumber,
+		CIURL:       req.CIURL,
+		Runner:      req.Runner,
+		StartedAt:   req.StartedAt,
+		FinishedAt:  req.FinishedAt,
+		Metadata:    req.Metadata,
+		BisectionID: req.BisectionID,
+	}
+	if run.FinishedAt == 0 {
+		run.FinishedAt = timestamp
+	}
+	if run.StartedAt == 0 {
+		run.StartedAt = run.FinishedAt
 	}
-	defer tx.Rollback()
-
-	query := `INSERT INTO benchmarks (repo, branch, commit_hash, component, value, timestamp) 
-	          VALUES (?, ?, ?, ?, ?, ?)`
 
-	for component, value := range req.Components {
-		_, err := tx.Exec(query, req.Repo, req.Branch, req.Commit, component, value, timestamp)
-		if err != nil {
-			return fmt.Errorf("failed to insert benchmark: %w", err)
+	var benchmarks []types.Benchmark
+	for _, m := range req.AllMeasurements() {
+		for _, value := range m.Samples {
+			benchmarks = append(benchmarks, types.Benchmark{
+				Repo:       req.Repo,
+				Branch:     req.Branch,
+				CommitHash: req.Commit,
+				Component:  m.Component,
+				Metric:     m.Metric,
+				Value:      value,
+				Timestamp:  timestamp,
+			})
 		}
 	}
 
-	return tx.Commit()
+	if err := d.store.CreateRun(run, benchmarks); err != nil {
+		return 0, err
+	}
+
+	return run.ID, nil
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"regression-ci/pkg/types"
+)
+
+const (
+	LabelIgnore     = "perf-ignore"
+	LabelStrict     = "perf-strict"
+	LabelAccepted   = "perf-accepted"
+	LabelRegression = "performance-regression"
+)
+
+func IsPolicyLabel(label string) bool {
+	switch label {
+	case LabelIgnore, LabelStrict, LabelAccepted:
+		return true
+	}
+	return false
+}
+
+func PolicyFromLabels(labels []string) *types.VerdictPolicy {
+	policy := &types.VerdictPolicy{}
+	for _, label := range labels {
+		switch label {
+		case LabelIgnore:
+			policy.Ignore = true
+		case LabelStrict:
+			policy.Strict = true
+		case LabelAccepted:
+			policy.Accepted = true
+		}
+	}
+
+	if *policy == (types.VerdictPolicy{}) {
+		return nil
+	}
+	return policy
+}
+
+// ApplyPolicy re-evaluates a stored analysis under a PR's label policy. The
+// response must hold the unmodified detector output, since strict mode
+// tightens the recorded threshold rather than the original one.
+func (d *Detector) ApplyPolicy(resp *types.AnalyzeResponse, policy *types.VerdictPolicy) {
+	resp.Policy = policy
+	detection := d.detection()
+	factor := detection.StrictFactor
+	if policy == nil || !policy.Strict || factor <= 0 {
+		return
+	}
+
+	for _, component := range resp.Components {
+		result := component.Result
+		if result == nil || result.Threshold <= 0 {
+			continue
+		}
+
+		result.Threshold *= factor
+		result.IsRegression = isRegression(result, component.Direction, detection.SignificanceLevel)
+	}
+}
+
+func (d *Detector) SetPRLabels(repo string, prNumber int, labels []string) error {
+	return d.store.SetPRLabels(repo, prNumber, labels)
+}
+
+func (d *Detector) PRLabels(repo string, prNumber int) ([]string, error) {
+	return d.store.PRLabels(repo, prNumber)
+}
+
+func (d *Detector) PRPolicy(repo string, prNumber int) (*types.VerdictPolicy, error) {
+	labels, err := d.PRLabels(repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return PolicyFromLabels(labels), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/storage"
+)
+
+const (
+	RepoFileSourcePush        = "push"
+	RepoFileSourcePullRequest = "pull_request"
+	RepoFileSourceAnalysis    = "analysis"
+)
+
+// RepoFileOrigin describes where a fetched repository config was seen. The
+// branch is empty when the fetch did not come with one.
+type RepoFileOrigin struct {
+	Commit        string
+	Branch        string
+	DefaultBranch bool
+	Source        string
+}
+
+// SaveRepoFile caches the outcome of fetching the repository config at a
+// commit. A nil file with no problems records that the commit has no file.
+func (d *Detector) SaveRepoFile(repo string, origin RepoFileOrigin, file *config.RepoFile, problems []string) error {
+	content := ""
+	if file != nil {
+		data, err := json.Marshal(file)
+		if err != nil {
+			return fmt.Errorf("failed to encode repo config: %w", err)
+		}
+		content = string(data)
+	}
+
+	if problems == nil {
+		problems = []string{}
+	}
+	encodedProblems, err := json.Marshal(problems)
+	if err != nil {
+		return fmt.Errorf("failed to encode repo config problems: %w", err)
+	}
+
+	return d.store.SaveRepoFile(storage.RepoFile{
+		Repo:          repo,
+		CommitHash:    origin.Commit,
+		Branch:        origin.Branch,
+		DefaultBranch: origin.DefaultBranch,
+		Source:        origin.Source,
+		Content:       content,
+		Problems:      string(encodedProblems),
+		FetchedAt:     time.Now().Unix(),
+	})
+}
+
+func (d *Detector) HasRepoFile(repo, commit string) bool {
+	_, err := d.store.RepoFile(repo, commit)
+	return err == nil
+}
+
+// RepoFile returns the valid repository config in effect for a commit on a
+// branch. When the commit itself was never fetched it falls back to the
+// latest push to the same branch, then to the latest push to the default
+// branch; a file pushed to any other branch never applies.
+func (d *Detector) RepoFile(repo, branch, commit string) (*config.RepoFile, error) {
+	stored, err := d.store.RepoFile(repo, commit)
+	if errors.Is(err, storage.ErrNotFound) && branch != "" {
+		stored, err = d.store.LatestRepoFile(repo, branch, RepoFileSourcePush)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		stored, err = d.store.LatestDefaultBranchRepoFile(repo, RepoFileSourcePush)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repo config: %w", err)
+	}
+
+	if stored.Content == "" {
+		return nil, nil
+	}
+
+	var file config.RepoFile
+	if err := json.Unmarshal([]byte(stored.Content), &file); err != nil {
+		return nil, fmt.Errorf("failed to decode repo config: %w", err)
+	}
+
+	return &file, nil
+}
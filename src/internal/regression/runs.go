@@ -0,0 +1,287 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+var (
+	ErrRunNotFound      = errors.New("run not found")
+	ErrAnalysisNotFound = errors.New("analysis not found")
+)
+
+// saveAnalysis keeps the detector's verdicts with the run. Failing to do so
+// loses history but not the analysis itself, so it is only logged.
+func (d *Detector) saveAnalysis(resp *types.AnalyzeResponse) {
+	results := make([]storage.AnalysisResult, 0, len(resp.Components))
+	for _, component := range resp.Components {
+		row := storage.AnalysisResult{
+			RunID:      resp.RunID,
+			Repo:       resp.Repo,
+			CommitHash: resp.Commit,
+			PRNumber:   resp.PRNumber,
+			Component:  component.Component,
+			Metric:     component.Metric,
+			Unit:       component.Unit,
+			Direction:  component.Direction,
+			Error:      component.Error,
+			CreatedAt:  resp.Timestamp,
+		}
+		if r := component.Result; r != nil {
+			row.IsRegression = r.IsRegression
+			row.CurrentValue = r.CurrentValue
+			row.BaselineValue = r.BaselineValue
+			row.PercentChange = r.PercentChange
+			row.AbsoluteChange = r.AbsoluteChange
+			row.ThresholdMode = r.ThresholdMode
+			row.Threshold = r.Threshold
+			row.ConfidenceScore = r.ConfidenceScore
+			row.SampleSize = r.SampleSize
+			row.PValue = r.PValue
+			row.BaselineSource = r.BaselineSource
+			row.BaselineCommit = r.BaselineCommit
+		}
+		results = append(results, row)
+	}
+
+	if err := d.store.SaveAnalysis(resp.RunID, results); err != nil {
+		log.Warn().Err(err).Int64("run_id", resp.RunID).Msg("failed to save analysis results")
+	}
+}
+
+// runAnalysis rebuilds the detector output for a run, or nil when the run
+// has none recorded.
+func (d *Detector) runAnalysis(run *types.Run) (*types.AnalyzeResponse, error) {
+	rows, err := d.store.RunAnalysis(run.ID)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	resp := &types.AnalyzeResponse{
+		Repo:       run.Repo,
+		Commit:     run.CommitHash,
+		PRNumber:   run.PRNumber,
+		RunID:      run.ID,
+		Components: make([]types.ComponentResult, 0, len(rows)),
+		Timestamp:  rows[0].CreatedAt,
+	}
+	for _, row := range rows {
+		component := types.ComponentResult{Component: row.Component, Metric: row.Metric, Unit: row.Unit,
+			Direction: row.Direction, Error: row.Error}
+		if row.Error == "" {
+			component.Result = &types.RegressionResult{
+				IsRegression:    row.IsRegression,
+				CurrentValue:    row.CurrentValue,
+				BaselineValue:   row.BaselineValue,
+				PercentChange:   row.PercentChange,
+				AbsoluteChange:  row.AbsoluteChange,
+				ThresholdMode:   row.ThresholdMode,
+				Threshold:       row.Threshold,
+				ConfidenceScore: row.ConfidenceScore,
+				SampleSize:      row.SampleSize,
+				PValue:          row.PValue,
+				BaselineSource:  row.BaselineSource,
+				BaselineCommit:  row.BaselineCommit,
+			}
+		}
+		resp.Components = append(resp.Components, component)
+	}
+
+	return resp, nil
+}
+
+func (d *Detector) Run(id int64) (*types.RunDetail, error) {
+	run, err := d.store.Run(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run: %w", err)
+	}
+
+	benchmarks, err := d.store.RunBenchmarks(id)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, err := d.runAnalysis(run)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := d.store.RunArtifacts(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RunDetail{Run: *run, Benchmarks: benchmarks, Analysis: analysis, Artifacts: artifacts}, nil
+}
+
+// CommitAnalysis returns the analysis from the most recent run of a commit.
+func (d *Detector) CommitAnalysis(repo, commit string) (*types.AnalyzeResponse, error) {
+	run, err := d.store.LatestCommitRun(repo, commit)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrAnalysisNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run: %w", err)
+	}
+
+	analysis, err := d.runAnalysis(run)
+	if err != nil {
+		return nil, err
+	}
+	if analysis == nil {
+		return nil, ErrAnalysisNotFound
+	}
+
+	return analysis, nil
+}
+
+// DeleteRun removes a run and rebuilds the baselines of the series it had
+// samples in, so they stop counting it. Artifact files are the caller's.
+func (d *Detector) DeleteRun(id int64) (*types.DeletedRun, error) {
+	detail, err := d.Run(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.store.DeleteRun(id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrRunNotFound
+		}
+		return nil, err
+	}
+
+	deleted := &types.DeletedRun{
+		Run:       detail.Run,
+		Samples:   len(detail.Benchmarks),
+		Artifacts: detail.Artifacts,
+		Baselines: []types.Baseline{},
+	}
+	if deleted.Artifacts == nil {
+		deleted.Artifacts = []types.Artifact{}
+	}
+
+	maxSamples := d.detection().MaxSamples
+	seen := make(map[storage.Series]bool)
+	for _, benchmark := range detail.Benchmarks {
+		series := storage.Series{Component: benchmark.Component, Metric: benchmark.Metric}
+		if seen[series] {
+			continue
+		}
+		seen[series] = true
+
+		baseline, err := d.refreshBaseline(detail.Run.Repo, series, maxSamples)
+		if err != nil {
+			return deleted, err
+		}
+		if baseline != nil {
+			deleted.Baselines = append(deleted.Baselines, *baseline)
+		}
+	}
+
+	return deleted, nil
+}
+
+// Reanalyze analyses the latest run of a commit again against the current
+// baselines and configuration, replacing its stored verdicts. Baselines are
+// never created or updated: the run's samples were counted when it was first
+// analysed, and a series without a baseline is reported as such rather than
+// seeded from the run under suspicion.
+func (d *Detector) Reanalyze(repo, commit string) (*types.AnalyzeResponse, error) {
+	run, err := d.store.LatestCommitRun(repo, commit)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run: %w", err)
+	}
+
+	benchmarks, err := d.store.RunBenchmarks(run.ID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := d.store.RunAnalysis(run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.AnalyzeResponse{
+		Repo:       run.Repo,
+		Commit:     run.CommitHash,
+		PRNumber:   run.PRNumber,
+		RunID:      run.ID,
+		Components: []types.ComponentResult{},
+		Timestamp:  time.Now().Unix(),
+	}
+
+	cfg := d.resolveConfig(repo, run.Branch, commit)
+	if cfg.enabled {
+		for _, m := range runMeasurements(benchmarks, previous) {
+			if _, _, enabled := cfg.component(m.Component); !enabled {
+				continue
+			}
+
+			componentResult := types.ComponentResult{
+				Component: m.Component,
+				Metric:    m.Metric,
+				Unit:      m.Unit,
+				Direction: m.Direction,
+			}
+			result, err := d.measure(repo, m, cfg)
+			if err != nil {
+				componentResult.Error = err.Error()
+			} else {
+				componentResult.Result = result
+			}
+			response.Components = append(response.Components, componentResult)
+		}
+	}
+
+	d.saveAnalysis(response)
+	return response, nil
+}
+
+// runMeasurements groups a run's samples back into measurements. Units and
+// directions are not stored with samples, so they come from the run's
+// previous verdicts.
+func runMeasurements(benchmarks []types.Benchmark, previous []storage.AnalysisResult) []types.Measurement {
+	known := make(map[storage.Series]storage.AnalysisResult, len(previous))
+	for _, row := range previous {
+		known[storage.Series{Component: row.Component, Metric: row.Metric}] = row
+	}
+
+	var measurements []types.Measurement
+	index := make(map[storage.Series]int)
+	for _, benchmark := range benchmarks {
+		series := storage.Series{Component: benchmark.Component, Metric: benchmark.Metric}
+		i, ok := index[series]
+		if !ok {
+			i = len(measurements)
+			index[series] = i
+			direction := known[series].Direction
+			if direction == "" {
+				direction = types.DirectionLower
+			}
+			measurements = append(measurements, types.Measurement{
+				Component: series.Component,
+				Metric:    series.Metric,
+				Unit:      known[series].Unit,
+				Direction: direction,
+			})
+		}
+		measurements[i].Samples = append(measurements[i].Samples, benchmark.Value)
+	}
+
+	return measurements
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// welchTTest returns the two-sided p-value for a difference in means
+// between a and b without assuming equal sizes or variances. It needs at
+// least two samples on each side.
+func welchTTest(a, b []float64) (float64, bool) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0, false
+	}
+
+	meanA, varA := stat.MeanVariance(a, nil)
+	meanB, varB := stat.MeanVariance(b, nil)
+	na, nb := float64(len(a)), float64(len(b))
+	errA, errB := varA/na, varB/nb
+
+	// Both sides are constant: the means either match exactly or differ
+	// with certainty.
+	if errA+errB == 0 {
+		if meanA == meanB {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	t := (meanA - meanB) / math.Sqrt(errA+errB)
+	df := (errA + errB) * (errA + errB) / (errA*errA/(na-1) + errB*errB/(nb-1))
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}
+
+	return 2 * dist.Survival(math.Abs(t)), true
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package regression
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"regression-ci/pkg/types"
+)
+
+// Units whose values can never be negative; anything else (deltas, scores,
+// unknown units) is accepted as-is.
+var nonNegativeUnits = map[string]bool{
+	"ns": true, "us": true, "µs": true, "ms": true, "s": true,
+	"ns/op": true, "b/op": true, "allocs/op": true, "mb/s": true,
+	"b": true, "bytes": true, "kb": true, "mb": true, "gb": true,
+	"allocs": true, "count": true, "ops/s": true, "rps": true, "%": true,
+	"b/s": true, "items/s": true,
+}
+
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid benchmark values: " + strings.Join(e.Problems, "; ")
+}
+
+func ValidateRequest(req types.AnalyzeRequest) error {
+	var problems []string
+
+	if len(req.Components) == 0 && len(req.Measurements) == 0 {
+		problems = append(problems, "at least one component is required")
+	}
+
+	components := make([]string, 0, len(req.Components))
+	for component := range req.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		value := req.Components[component]
+		unit := req.Units[component]
+
+		switch {
+		case math.IsNaN(value):
+			problems = append(problems, fmt.Sprintf("component %q: value is NaN", component))
+		case math.IsInf(value, 0):
+			problems = append(problems, fmt.Sprintf("component %q: value is infinite", component))
+		case value < 0 && nonNegativeUnits[strings.ToLower(unit)]:
+			problems = append(problems, fmt.Sprintf("component %q: negative value %g is impossible for unit %q", component, value, unit))
+		}
+	}
+
+	for component := range req.Units {
+		if _, ok := req.Components[component]; !ok {
+			problems = append(problems, fmt.Sprintf("unit given for unknown component %q", component))
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	for component := range req.Components {
+		seen[[2]string{component, types.DefaultMetric}] = true
+	}
+	for i, m := range req.Measurements {
+		if m.Metric == "" {
+			m.Metric = types.DefaultMetric
+		}
+		name := fmt.Sprintf("measurement %d (%s %s)", i, m.Component, m.Metric)
+
+		if m.Component == "" {
+			problems = append(problems, fmt.Sprintf("measurement %d: component is required", i))
+		}
+		if len(m.Samples) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one sample is required", name))
+		}
+		if m.Direction != "" && m.Direction != types.DirectionLower && m.Direction != types.DirectionHigher {
+			problems = append(problems, fmt.Sprintf("%s: unknown direction %q", name, m.Direction))
+		}
+
+		key := [2]string{m.Component, m.Metric}
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("%s: reported more than once", name))
+		}
+		seen[key] = true
+
+		for _, value := range m.Samples {
+			switch {
+			case math.IsNaN(value):
+				problems = append(problems, fmt.Sprintf("%s: sample is NaN", name))
+			case math.IsInf(value, 0):
+				problems = append(problems, fmt.Sprintf("%s: sample is infinite", name))
+			case value < 0 && nonNegativeUnits[strings.ToLower(m.Unit)]:
+				problems = append(problems, fmt.Sprintf("%s: negative sample %g is impossible for unit %q", name, value, m.Unit))
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
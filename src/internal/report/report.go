@@ -0,0 +1,182 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"regression-ci/pkg/types"
+)
+
+const (
+	Marker = "<!-- regression-ci:report -->"
+
+	ConclusionSuccess = "success"
+	ConclusionFailure = "failure"
+	ConclusionNeutral = "neutral"
+)
+
+func Regressions(resp *types.AnalyzeResponse) (open, acknowledged int) {
+	acceptAll := resp.Policy != nil && resp.Policy.Accepted
+	for _, component := range resp.Components {
+		if component.Result == nil || !component.Result.IsRegression {
+			continue
+		}
+		if component.Acknowledgement != nil || acceptAll {
+			acknowledged++
+		} else {
+			open++
+		}
+	}
+	return open, acknowledged
+}
+
+func Conclusion(resp *types.AnalyzeResponse) string {
+	if resp.Policy != nil && resp.Policy.Ignore {
+		return ConclusionNeutral
+	}
+	if open, _ := Regressions(resp); open > 0 {
+		return ConclusionFailure
+	}
+	return ConclusionSuccess
+}
+
+func Title(resp *types.AnalyzeResponse) string {
+	open, acknowledged := Regressions(resp)
+	switch {
+	case resp.Policy != nil && resp.Policy.Ignore:
+		return "Performance verdict skipped (perf-ignore)"
+	case open > 0:
+		return fmt.Sprintf("%d performance regression(s) detected", open)
+	case acknowledged > 0:
+		return fmt.Sprintf("%d performance regression(s) accepted", acknowledged)
+	default:
+		return "No performance regressions"
+	}
+}
+
+func Markdown(resp *types.AnalyzeResponse) string {
+	var b strings.Builder
+
+	b.WriteString(Marker + "\n")
+	fmt.Fprintf(&b, "## Performance report for `%s`\n\n", shortSHA(resp.Commit))
+	fmt.Fprintf(&b, "**%s**\n\n", Title(resp))
+	if resp.Policy != nil && resp.Policy.Strict {
+		b.WriteString("Strict thresholds apply to this PR (`perf-strict`).\n\n")
+	}
+
+	components := make([]types.ComponentResult, len(resp.Components))
+	copy(components, resp.Components)
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Component != components[j].Component {
+			return components[i].Component < components[j].Component
+		}
+		return components[i].Metric < components[j].Metric
+	})
+
+	b.WriteString("| Component | Baseline | Current | Change | Verdict |\n")
+	b.WriteString("|---|---:|---:|---:|---|\n")
+	for _, component := range components {
+		if component.Result == nil {
+			fmt.Fprintf(&b, "| %s | | | | :warning: %s |\n", seriesName(component), component.Error)
+			continue
+		}
+
+		result := component.Result
+		fmt.Fprintf(&b, "| %s | %.4g | %.4g | %s | %s |\n", seriesName(component),
+			result.BaselineValue, result.CurrentValue, formatChange(result), verdict(resp.Policy, component))
+	}
+
+	writeProfileDiffs(&b, components)
+
+	var acks []*types.Acknowledgement
+	seen := make(map[int64]bool)
+	for _, component := range components {
+		if ack := component.Acknowledgement; ack != nil && !seen[ack.ID] {
+			seen[ack.ID] = true
+			acks = append(acks, ack)
+		}
+	}
+
+	if len(acks) > 0 {
+		b.WriteString("\n### Accepted regressions\n\n")
+		for _, ack := range acks {
+			line := fmt.Sprintf("- @%s accepted `%s`", ack.Author, ack.Component)
+			if ack.Component == "*" {
+				line = fmt.Sprintf("- @%s ignored all regressions", ack.Author)
+			}
+			if ack.Reason != "" {
+				line += ": " + ack.Reason
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	b.WriteString("\n<sub>Comment `/perf accept <component> <reason>`, `/perf ignore <reason>` or `/perf rerun` to respond.</sub>\n")
+
+	return b.String()
+}
+
+// writeProfileDiffs lists, per regressed component with profiles, the
+// functions whose share of the profile moved most since the baseline run.
+func writeProfileDiffs(b *strings.Builder, components []types.ComponentResult) {
+	header := false
+	for _, component := range components {
+		for _, diff := range component.ProfileDiffs {
+			if !header {
+				b.WriteString("\n### Profile diffs\n")
+				header = true
+			}
+			fmt.Fprintf(b, "\n<details><summary>%s %s profile vs run %d (%s)</summary>\n\n",
+				seriesName(component), diff.Kind, diff.BaselineRunID, diff.SampleType)
+			b.WriteString("| Function | Baseline | Current | Delta |\n")
+			b.WriteString("|---|---:|---:|---:|\n")
+			for _, function := range diff.Functions {
+				fmt.Fprintf(b, "| `%s` | %.2f%% | %.2f%% | %+.2f pp |\n",
+					function.Function, function.Baseline, function.Current, function.Delta)
+			}
+			b.WriteString("\n</details>\n")
+		}
+	}
+}
+
+// seriesName labels a row with its metric unless the component only
+// reports the default one.
+func seriesName(component types.ComponentResult) string {
+	if component.Metric == "" || component.Metric == types.DefaultMetric {
+		return "`" + component.Component + "`"
+	}
+	return fmt.Sprintf("`%s` %s", component.Component, component.Metric)
+}
+
+func formatChange(result *types.RegressionResult) string {
+	if result.ThresholdMode == "absolute" {
+		return fmt.Sprintf("%+.4g", result.AbsoluteChange)
+	}
+	return fmt.Sprintf("%+.2f%%", result.PercentChange)
+}
+
+func verdict(policy *types.VerdictPolicy, component types.ComponentResult) string {
+	switch {
+	case !component.Result.IsRegression:
+		return ":white_check_mark: ok"
+	case component.Acknowledgement != nil:
+		return ":ballot_box_with_check: accepted by @" + component.Acknowledgement.Author
+	case policy != nil && policy.Accepted:
+		return ":ballot_box_with_check: accepted (`perf-accepted`)"
+	case policy != nil && policy.Ignore:
+		return ":grey_question: regression (ignored)"
+	default:
+		return ":x: regression"
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
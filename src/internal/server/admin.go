@@ -0,0 +1,250 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/backup"
+	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
+	"regression-ci/internal/transfer"
+	"regression-ci/pkg/types"
+)
+
+const defaultHistoryLimit = 50
+
+// requireAdmin guards operator endpoints with the admin bearer token. With
+// no token configured the admin API is switched off entirely.
+func (s *Server) requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := s.currentConfig().Admin.Token
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "admin API disabled",
+			})
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid admin token",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (s *Server) createBackup(c *gin.Context) {
+	info, err := s.backup()
+	if errors.Is(err, storage.ErrBackupUnsupported) {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("backup failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "backup failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, info)
+}
+
+func (s *Server) backup() (*backup.Info, error) {
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	cfg := s.currentConfig().Backup
+	return backup.Create(s.store, cfg.Dir, cfg.Keep)
+}
+
+// exportRepo streams a repository's history as a tar.gz bundle. Errors after
+// the first byte can only be logged; the client sees a truncated archive.
+func (s *Server) exportRepo(c *gin.Context) {
+	repo := c.Param("repo")
+	format := c.DefaultQuery("format", transfer.FormatJSONL)
+	if !transfer.ValidFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be jsonl or csv",
+		})
+		return
+	}
+
+	name := strings.ReplaceAll(repo, "/", "_")
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.tar.gz"`, name, format))
+	c.Status(http.StatusOK)
+
+	if err := transfer.Export(s.store, repo, format, c.Writer); err != nil {
+		log.Error().Err(err).Str("repo", repo).Msg("export failed")
+	}
+}
+
+func (s *Server) importRepo(c *gin.Context) {
+	result, err := transfer.Import(s.store, c.Request.Body, c.Query("repo"))
+	if errors.Is(err, transfer.ErrInvalidBundle) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  err.Error(),
+			"result": result,
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("import failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "import failed",
+			"result": result,
+		})
+		return
+	}
+
+	log.Info().Str("repo", result.Repo).Interface("tables", result.Tables).Msg("bundle imported")
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) vacuum(c *gin.Context) {
+	if err := s.store.Vacuum(); err != nil {
+		log.Error().Err(err).Msg("database vacuum failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "vacuum failed",
+		})
+		return
+	}
+
+	log.Info().Msg("vacuumed database")
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) listRepos(c *gin.Context) {
+	repos, err := s.store.Repos()
+	if err != nil {
+		log.Error().Err(err).Msg("repo listing failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "repo listing failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, repos)
+}
+
+func (s *Server) listSeries(c *gin.Context) {
+	series, err := s.detector.Series(c.Param("repo"))
+	if err != nil {
+		log.Error().Err(err).Str("repo", c.Param("repo")).Msg("series listing failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "series listing failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// seriesHistory returns a series' most recent samples, newest first.
+func (s *Server) seriesHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be a positive integer",
+		})
+		return
+	}
+
+	history, err := s.store.RecentBenchmarks(c.Param("repo"), c.Param("component"), baselineMetric(c), limit)
+	if err != nil {
+		log.Error().Err(err).Str("repo", c.Param("repo")).Msg("history retrieval failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "history retrieval failed",
+		})
+		return
+	}
+	if history == nil {
+		history = []types.Benchmark{}
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// reanalyzeCommit re-runs the analysis of a commit's latest run, for after
+// its baselines were reset or pinned. When the commit is still its PR's head
+// it updates the PR report like a new upload would; older commits leave the
+// PR alone.
+func (s *Server) reanalyzeCommit(c *gin.Context) {
+	result, err := s.detector.Reanalyze(c.Param("repo"), c.Param("sha"))
+	if errors.Is(err, regression.ErrRunNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "no run for commit",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("repo", c.Param("repo")).Msg("reanalysis failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "reanalysis failed",
+		})
+		return
+	}
+
+	if kept, err := s.store.RunArtifacts(result.RunID); err == nil {
+		s.diffProfiles(result, kept)
+	}
+	if result.PRNumber > 0 {
+		stored, _, err := s.detector.PRReport(result.Repo, result.PRNumber)
+		if err == nil && stored.HeadSHA == c.Param("sha") {
+			s.recordPRAnalysis(c.Request.Context(), result)
+		} else if err := s.evaluatePR(result); err != nil {
+			log.Warn().Err(err).Str("repo", result.Repo).Int("pr", result.PRNumber).Msg("failed to evaluate PR policy")
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) deleteRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid run id",
+		})
+		return
+	}
+
+	deleted, err := s.detector.DeleteRun(id)
+	if errors.Is(err, regression.ErrRunNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "run not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Int64("run_id", id).Msg("run deletion failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "run deletion failed",
+		})
+		return
+	}
+
+	if err := s.artifactStore().RemoveRun(id); err != nil {
+		log.Warn().Err(err).Int64("run_id", id).Msg("failed to remove run artifacts")
+	}
+
+	log.Info().Int64("run_id", id).Str("repo", deleted.Run.Repo).Int("samples", deleted.Samples).Msg("deleted run")
+	c.JSON(http.StatusOK, deleted)
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/artifacts"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func (s *Server) artifactStore() *artifacts.Store {
+	return artifacts.New(s.currentConfig().Artifacts.Dir)
+}
+
+// keepArtifacts attaches an upload's artifacts to the run just recorded.
+// Like the analysis history, losing them is logged rather than failing the
+// upload the verdict was already computed for.
+func (s *Server) keepArtifacts(resp *types.AnalyzeResponse, uploads *artifacts.Batch) []types.Artifact {
+	kept, err := uploads.Keep(resp.Repo, resp.RunID)
+	if err == nil {
+		err = s.store.SaveArtifacts(kept)
+	}
+	if err != nil {
+		log.Warn().Err(err).Int64("run_id", resp.RunID).Msg("failed to keep run artifacts")
+		return nil
+	}
+	return kept
+}
+
+func (s *Server) getArtifact(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid run id",
+		})
+		return
+	}
+
+	artifact, err := s.store.Artifact(id, c.Param("name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "artifact not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Int64("run_id", id).Msg("artifact retrieval failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "artifact retrieval failed",
+		})
+		return
+	}
+
+	file, err := s.artifactStore().Open(id, artifact.Name)
+	if err != nil {
+		log.Error().Err(err).Int64("run_id", id).Str("artifact", artifact.Name).Msg("artifact content missing")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "artifact retrieval failed",
+		})
+		return
+	}
+	defer file.Close()
+
+	contentType := artifact.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, artifact.Size, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", artifact.Name),
+	})
+}
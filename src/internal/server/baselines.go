@@ -0,0 +1,100 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+func (s *Server) getBaseline(c *gin.Context) {
+	provenance, err := s.detector.BaselineProvenance(c.Param("repo"), c.Param("component"), baselineMetric(c))
+	if err != nil {
+		s.baselineError(c, err, "baseline retrieval failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, provenance)
+}
+
+func (s *Server) pinBaseline(c *gin.Context) {
+	var req types.PinBaselineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request format",
+		})
+		return
+	}
+
+	provenance, err := s.detector.PinBaseline(c.Param("repo"), c.Param("component"), baselineMetric(c), req.Commit)
+	if err != nil {
+		s.baselineError(c, err, "baseline pin failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, provenance)
+}
+
+func (s *Server) resetBaseline(c *gin.Context) {
+	if err := s.detector.ResetBaseline(c.Param("repo"), c.Param("component"), baselineMetric(c)); err != nil {
+		s.baselineError(c, err, "baseline reset failed")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// rebuildBaselines leaves pinned baselines alone unless ?force is given.
+func (s *Server) rebuildBaselines(c *gin.Context) {
+	force := false
+	if value, ok := c.GetQuery("force"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if value != "" && err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid force",
+			})
+			return
+		}
+		force = value == "" || parsed
+	}
+
+	baselines, pinned, err := s.detector.RebuildBaselines(c.Param("repo"), force)
+	if err != nil {
+		s.baselineError(c, err, "baseline rebuild failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repo":      c.Param("repo"),
+		"baselines": baselines,
+		"pinned":    pinned,
+	})
+}
+
+// baselineMetric picks the series of a component a baseline request is
+// about; components posted through /analyze only have the default one.
+func baselineMetric(c *gin.Context) string {
+	return c.DefaultQuery("metric", types.DefaultMetric)
+}
+
+func (s *Server) baselineError(c *gin.Context, err error, message string) {
+	if errors.Is(err, regression.ErrBaselineNotFound) || errors.Is(err, regression.ErrNoSamples) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.Error().Err(err).Str("repo", c.Param("repo")).Msg(message)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": message,
+	})
+}
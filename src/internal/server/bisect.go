@@ -0,0 +1,212 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/github"
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+const bisectEventType = "regression-ci-bisect"
+
+func (s *Server) listBisections(c *gin.Context) {
+	bisections, err := s.detector.Bisections(c.Param("repo"))
+	if err != nil {
+		log.Error().Err(err).Str("repo", c.Param("repo")).Msg("bisection listing failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "bisection listing failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, bisections)
+}
+
+// trackBisections advances the bisection a midpoint run was dispatched for,
+// or starts one for a regression on the default branch. Only runs carrying
+// the bisection's id advance it; midpoint runs never start another.
+func (s *Server) trackBisections(ctx context.Context, req types.AnalyzeRequest, resp *types.AnalyzeResponse) {
+	if !s.githubClient().Enabled() {
+		return
+	}
+
+	if req.BisectionID != 0 {
+		pending, err := s.detector.PendingBisections(req.Repo, req.Commit)
+		if err != nil {
+			log.Warn().Err(err).Str("repo", req.Repo).Msg("failed to load pending bisections")
+			return
+		}
+
+		for i := range pending {
+			if pending[i].ID != req.BisectionID {
+				continue
+			}
+			value, ok := req.Value(pending[i].Component, pending[i].Metric)
+			if !ok {
+				continue
+			}
+			if err := s.advanceBisection(ctx, &pending[i], value); err != nil {
+				log.Warn().Err(err).Int64("bisection", pending[i].ID).Msg("failed to advance bisection")
+			}
+		}
+		return
+	}
+
+	if err := s.startBisection(ctx, req, resp); err != nil {
+		log.Warn().Err(err).Str("repo", req.Repo).Str("commit", req.Commit).Msg("failed to start bisection")
+	}
+}
+
+func (s *Server) startBisection(ctx context.Context, req types.AnalyzeRequest, resp *types.AnalyzeResponse) error {
+	worst := worstRegression(resp)
+	if worst == nil {
+		return nil
+	}
+
+	owner, name, err := github.SplitRepo(req.Repo)
+	if err != nil {
+		return err
+	}
+
+	repository, err := s.githubClient().GetRepository(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	if req.Branch != repository.GetDefaultBranch() {
+		return nil
+	}
+
+	if exists, err := s.detector.HasBisection(req.Repo, req.Commit); err != nil || exists {
+		return err
+	}
+
+	lastGood, err := s.detector.LastSampleBefore(req.Repo, req.Branch, worst.Component, worst.Metric, req.Commit)
+	if err != nil {
+		return nil
+	}
+
+	candidates, err := s.githubClient().CompareCommits(ctx, owner, name, lastGood.CommitHash, req.Commit)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	bisection := &types.Bisection{
+		Repo:       req.Repo,
+		Branch:     req.Branch,
+		Component:  worst.Component,
+		Metric:     worst.Metric,
+		GoodCommit: lastGood.CommitHash,
+		BadCommit:  req.Commit,
+		GoodValue:  lastGood.Value,
+		BadValue:   worst.Result.CurrentValue,
+		Candidates: candidates,
+		Low:        0,
+		High:       len(candidates) - 1,
+		Status:     regression.BisectionCandidates,
+	}
+
+	switch {
+	case len(candidates) == 1:
+		bisection.Status = regression.BisectionComplete
+		bisection.Culprit = candidates[0]
+	case s.currentConfig().GitHub.BisectDispatch:
+		bisection.Status = regression.BisectionRunning
+		bisection.PendingCommit = regression.NextBisectionCommit(bisection)
+	}
+
+	if err := s.detector.CreateBisection(bisection); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("repo", req.Repo).
+		Str("component", worst.Component).
+		Str("good", lastGood.CommitHash).
+		Str("bad", req.Commit).
+		Int("candidates", len(candidates)).
+		Msg("regression bisection started")
+
+	return s.continueBisection(ctx, bisection)
+}
+
+func (s *Server) advanceBisection(ctx context.Context, bisection *types.Bisection, value float64) error {
+	regression.RecordBisectionSample(bisection, value)
+	if bisection.Status != regression.BisectionComplete {
+		bisection.PendingCommit = regression.NextBisectionCommit(bisection)
+	}
+
+	if err := s.detector.UpdateBisection(bisection); err != nil {
+		return err
+	}
+
+	return s.continueBisection(ctx, bisection)
+}
+
+func (s *Server) continueBisection(ctx context.Context, bisection *types.Bisection) error {
+	owner, name, err := github.SplitRepo(bisection.Repo)
+	if err != nil {
+		return err
+	}
+
+	switch bisection.Status {
+	case regression.BisectionRunning:
+		payload := map[string]interface{}{
+			"bisection_id": bisection.ID,
+			"sha":          bisection.PendingCommit,
+			"component":    bisection.Component,
+		}
+		return s.githubClient().Dispatch(ctx, owner, name, bisectEventType, payload)
+
+	case regression.BisectionComplete:
+		log.Info().
+			Str("repo", bisection.Repo).
+			Str("component", bisection.Component).
+			Str("culprit", bisection.Culprit).
+			Msg("regression bisected to culprit commit")
+
+		body := fmt.Sprintf("Performance regression in `%s` was bisected to this commit.\n\n"+
+			"- last good: `%s` (%.4g)\n- first bad sample: `%s` (%.4g)\n- candidates examined: %d",
+			bisection.Component, bisection.GoodCommit, bisection.GoodValue,
+			bisection.BadCommit, bisection.BadValue, len(bisection.Candidates))
+		return s.githubClient().CreateCommitComment(ctx, owner, name, bisection.Culprit, body)
+	}
+
+	return nil
+}
+
+func worstRegression(resp *types.AnalyzeResponse) *types.ComponentResult {
+	var worst *types.ComponentResult
+	for i := range resp.Components {
+		component := &resp.Components[i]
+		if component.Result == nil || !component.Result.IsRegression {
+			continue
+		}
+		if worst == nil || severity(component.Result) > severity(worst.Result) {
+			worst = component
+		}
+	}
+	return worst
+}
+
+func severity(result *types.RegressionResult) float64 {
+	if result.Threshold > 0 {
+		if result.ThresholdMode == regression.ThresholdModeAbsolute {
+			return math.Abs(result.AbsoluteChange) / result.Threshold
+		}
+		return math.Abs(result.PercentChange) / result.Threshold
+	}
+	return math.Abs(result.PercentChange)
+}
@@ -4,17 +4,20 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 
+	"regression-ci/internal/artifacts"
+	"regression-ci/internal/regression"
 	"regression-ci/pkg/types"
 )
 
 func (s *Server) healthCheck(c *gin.Context) {
-	if err := s.db.Ping(); err != nil {
+	if err := s.store.Ping(); err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"status": "unhealthy",
 			"error":  "database connection failed",
@@ -28,21 +31,40 @@ func (s *Server) healthCheck(c *gin.Context) {
 	})
 }
 
-func (s *Server) handleWebhook(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": "webhook handling not implemented",
-	})
-}
-
 func (s *Server) analyzeEndpoint(c *gin.Context) {
 	var req types.AnalyzeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if isTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "invalid request format",
 		})
 		return
 	}
 
+	s.analyze(c, req, nil)
+}
+
+// analyze validates and analyzes a request however it was uploaded, then
+// hands the verdict to PR reporting or bisection tracking. Artifacts
+// uploaded with the request are kept once the run has been recorded.
+func (s *Server) analyze(c *gin.Context, req types.AnalyzeRequest, uploads *artifacts.Batch) {
+	if err := regression.ValidateRequest(req); err != nil {
+		var invalid *regression.ValidationError
+		if !errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    "invalid benchmark values",
+			"problems": invalid.Problems,
+		})
+		return
+	}
+
+	s.ensureRepoFile(c.Request.Context(), req.Repo, req.Branch, req.Commit)
+
 	result, err := s.detector.Analyze(req)
 	if err != nil {
 		log.Error().Err(err).Msg("analysis failed")
@@ -52,6 +74,17 @@ func (s *Server) analyzeEndpoint(c *gin.Context) {
 		return
 	}
 
+	if uploads != nil && uploads.Len() > 0 {
+		kept := s.keepArtifacts(result, uploads)
+		s.diffProfiles(result, kept)
+	}
+
+	if req.PRNumber > 0 {
+		s.recordPRAnalysis(c.Request.Context(), result)
+	} else {
+		s.trackBisections(c.Request.Context(), req, result)
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
@@ -0,0 +1,200 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/artifacts"
+	"regression-ci/internal/formats"
+	"regression-ci/internal/profiles"
+	"regression-ci/pkg/types"
+)
+
+var errInvalidUpload = errors.New("invalid multipart upload")
+
+// ingestEndpoint analyzes raw benchmark tool output posted as the request
+// body, or as the results part of a multipart upload carrying artifacts,
+// with the run described by query parameters. The format query parameter
+// names the tool; without it the format comes from the Content-Type or is
+// sniffed.
+func (s *Server) ingestEndpoint(c *gin.Context) {
+	req := types.AnalyzeRequest{
+		Repo:   c.Query("repo"),
+		Branch: c.Query("branch"),
+		Commit: c.Query("commit"),
+		CIURL:  c.Query("ci_url"),
+		Runner: c.Query("runner"),
+	}
+	if req.Repo == "" || req.Branch == "" || req.Commit == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "repo, branch and commit are required",
+		})
+		return
+	}
+
+	if pr := c.Query("pr_number"); pr != "" {
+		number, err := strconv.Atoi(pr)
+		if err != nil || number < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid pr_number",
+			})
+			return
+		}
+		req.PRNumber = number
+	}
+
+	if bisection := c.Query("bisection_id"); bisection != "" {
+		id, err := strconv.ParseInt(bisection, 10, 64)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid bisection_id",
+			})
+			return
+		}
+		req.BisectionID = id
+	}
+
+	uploads := s.artifactStore().NewBatch()
+	defer uploads.Discard()
+
+	var result *formats.Result
+	var err error
+	if c.ContentType() == "multipart/form-data" {
+		result, err = readMultipart(c, uploads)
+	} else {
+		result, err = formats.Parse(c.Query("format"), c.ContentType(), c.Request.Body)
+	}
+	if err != nil {
+		if isTooLarge(c, err) {
+			return
+		}
+		if errors.Is(err, formats.ErrUnknownFormat) || errors.Is(err, formats.ErrNoBenchmarks) ||
+			errors.Is(err, formats.ErrMalformed) || errors.Is(err, errInvalidUpload) ||
+			errors.Is(err, artifacts.ErrInvalidName) || errors.Is(err, artifacts.ErrDuplicateName) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		log.Warn().Err(err).Str("repo", req.Repo).Msg("benchmark ingestion failed")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "failed to read benchmark output",
+		})
+		return
+	}
+
+	req.Measurements = result.Measurements
+	req.Metadata = result.Metadata
+
+	s.analyze(c, req, uploads)
+}
+
+// readMultipart streams a multipart/form-data upload. The "results" part is
+// parsed like a plain /ingest body, with its own Content-Type standing in
+// for the request's; every "artifact" part is staged under its file name,
+// and every "profile[<component>]" part as that component's pprof profile.
+// Other parts are ignored.
+func readMultipart(c *gin.Context, uploads *artifacts.Batch) (*formats.Result, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidUpload, err)
+	}
+
+	var result *formats.Result
+	var profiled []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart upload: %w", err)
+		}
+
+		switch part.FormName() {
+		case "results":
+			if result != nil {
+				err = fmt.Errorf("%w: more than one results part", errInvalidUpload)
+				break
+			}
+			contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			result, err = formats.Parse(c.Query("format"), contentType, part)
+		case "artifact":
+			err = uploads.Add(types.Artifact{
+				Name:        part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+			}, part)
+		default:
+			if component, ok := profileComponent(part.FormName()); ok {
+				profiled = append(profiled, component)
+				err = addProfile(uploads, component, part)
+			}
+		}
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("%w: missing results part", errInvalidUpload)
+	}
+
+	measured := make(map[string]bool)
+	for _, m := range result.Measurements {
+		measured[m.Component] = true
+	}
+	for _, component := range profiled {
+		if !measured[component] {
+			return nil, fmt.Errorf("%w: profile for %q, which has no results", errInvalidUpload, component)
+		}
+	}
+
+	return result, nil
+}
+
+func profileComponent(formName string) (string, bool) {
+	if !strings.HasPrefix(formName, "profile[") || !strings.HasSuffix(formName, "]") {
+		return "", false
+	}
+	component := formName[len("profile[") : len(formName)-1]
+	return component, component != ""
+}
+
+// addProfile checks that a part is a pprof profile before staging it, named
+// after its component and kind so a run holds one profile of each kind.
+func addProfile(uploads *artifacts.Batch, component string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read profile for %s: %w", component, err)
+	}
+	p, err := profiles.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidUpload, component, err)
+	}
+
+	kind := profiles.Kind(p)
+	name := strings.NewReplacer("/", "_", `\`, "_").Replace(component) + "." + kind + ".pprof"
+	return uploads.Add(types.Artifact{
+		Name:        name,
+		ContentType: "application/vnd.google.protobuf",
+		Component:   component,
+		Kind:        kind,
+	}, bytes.NewReader(data))
+}
+
+func (s *Server) listFormats(c *gin.Context) {
+	c.JSON(http.StatusOK, formats.All())
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const idleMaintenanceInterval = time.Hour
+
+// runMaintenance downsamples expired samples on every tick and vacuums once
+// per vacuum interval if anything was removed since the last vacuum. The
+// retention config is re-read each tick so reloads apply without a restart.
+func (s *Server) runMaintenance(ctx context.Context) {
+	lastVacuum := time.Now()
+	removedSinceVacuum := 0
+
+	for {
+		retention := s.currentConfig().Retention
+		interval := retention.Interval
+		if retention.RawDays <= 0 || interval <= 0 {
+			interval = idleMaintenanceInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		retention = s.currentConfig().Retention
+		if retention.RawDays <= 0 {
+			continue
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -retention.RawDays).Unix()
+		stats, err := s.store.DownsampleBenchmarks(cutoff, retention.Granularity)
+		if err != nil {
+			log.Error().Err(err).Msg("benchmark downsampling failed")
+			continue
+		}
+		if stats.SamplesRemoved > 0 {
+			log.Info().Int("samples_removed", stats.SamplesRemoved).
+				Int("aggregates_written", stats.AggregatesWritten).
+				Str("granularity", retention.Granularity).Msg("downsampled expired benchmarks")
+		}
+		removedSinceVacuum += stats.SamplesRemoved
+
+		if retention.VacuumInterval > 0 && removedSinceVacuum > 0 && time.Since(lastVacuum) >= retention.VacuumInterval {
+			if err := s.store.Vacuum(); err != nil {
+				log.Error().Err(err).Msg("database vacuum failed")
+				continue
+			}
+			log.Info().Int("samples_removed", removedSinceVacuum).Msg("vacuumed database")
+			lastVacuum = time.Now()
+			removedSinceVacuum = 0
+		}
+	}
+}
+
+// runBackups takes a scheduled backup every backup interval. Like
+// maintenance, it re-reads the config so the schedule can be changed live.
+func (s *Server) runBackups(ctx context.Context) {
+	for {
+		interval := s.currentConfig().Backup.Interval
+		enabled := interval > 0
+		if !enabled {
+			interval = idleMaintenanceInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		if !enabled || s.currentConfig().Backup.Interval <= 0 {
+			continue
+		}
+
+		info, err := s.backup()
+		if err != nil {
+			log.Error().Err(err).Msg("scheduled backup failed")
+			continue
+		}
+		log.Info().Str("path", info.Path).Int64("size", info.Size).Msg("database backed up")
+	}
+}
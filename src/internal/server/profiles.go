@@ -0,0 +1,88 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/pprof/profile"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/profiles"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+// diffProfiles compares the profiles uploaded for each regressed component
+// with the baseline run's profiles of the same kind. A component regressing
+// in several metrics gets its diffs once, on the first of them.
+func (s *Server) diffProfiles(resp *types.AnalyzeResponse, kept []types.Artifact) {
+	byComponent := make(map[string][]types.Artifact)
+	for _, artifact := range kept {
+		if artifact.Component != "" {
+			byComponent[artifact.Component] = append(byComponent[artifact.Component], artifact)
+		}
+	}
+
+	for i := range resp.Components {
+		component := &resp.Components[i]
+		if component.Result == nil || !component.Result.IsRegression {
+			continue
+		}
+
+		for _, current := range byComponent[component.Component] {
+			diff, err := s.diffProfile(resp.Repo, component.Metric, current)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Warn().Err(err).Int64("run_id", resp.RunID).Str("component", component.Component).
+					Str("kind", current.Kind).Msg("failed to diff profiles")
+				continue
+			}
+			component.ProfileDiffs = append(component.ProfileDiffs, *diff)
+		}
+		delete(byComponent, component.Component)
+	}
+}
+
+func (s *Server) diffProfile(repo, metric string, current types.Artifact) (*types.ProfileDiff, error) {
+	base, err := s.store.BaselineProfile(repo, current.Component, metric, current.Kind, current.RunID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseProfile, err := s.readProfile(*base)
+	if err != nil {
+		return nil, err
+	}
+	currentProfile, err := s.readProfile(current)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := profiles.Diff(baseProfile, currentProfile)
+	if err != nil {
+		return nil, err
+	}
+	diff.Kind = current.Kind
+	diff.BaselineRunID = base.RunID
+	return diff, nil
+}
+
+func (s *Server) readProfile(artifact types.Artifact) (*profile.Profile, error) {
+	file, err := s.artifactStore().Open(artifact.RunID, artifact.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile of run %d: %w", artifact.RunID, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile of run %d: %w", artifact.RunID, err)
+	}
+	return profiles.Parse(data)
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/github"
+	"regression-ci/internal/regression"
+	"regression-ci/internal/report"
+)
+
+// syncRepoFile fetches and validates .regression-ci.yml at the origin's
+// commit and caches the outcome for the detector. It reports whether the
+// file exists.
+func (s *Server) syncRepoFile(ctx context.Context, repo string, origin regression.RepoFileOrigin) (bool, []string, error) {
+	owner, name, err := github.SplitRepo(repo)
+	if err != nil {
+		return false, nil, err
+	}
+
+	data, err := s.githubClient().GetFileContents(ctx, owner, name, config.RepoFileName, origin.Commit)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if data == nil {
+		return false, nil, s.detector.SaveRepoFile(repo, origin, nil, nil)
+	}
+
+	file, problems := config.ParseRepoFile(data)
+	if err := s.detector.SaveRepoFile(repo, origin, file, problems); err != nil {
+		return true, problems, err
+	}
+
+	return true, problems, nil
+}
+
+func (s *Server) ensureRepoFile(ctx context.Context, repo, branch, sha string) {
+	if !s.githubClient().Enabled() || s.detector.HasRepoFile(repo, sha) {
+		return
+	}
+
+	origin := regression.RepoFileOrigin{Commit: sha, Branch: branch, Source: regression.RepoFileSourceAnalysis}
+	if _, _, err := s.syncRepoFile(ctx, repo, origin); err != nil {
+		log.Warn().Err(err).Str("repo", repo).Str("commit", sha).Msg("failed to fetch repository config")
+	}
+}
+
+func (s *Server) reportRepoFile(ctx context.Context, repo, sha string, problems []string) error {
+	owner, name, err := github.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	check := github.CheckRunResult{
+		Name:       github.ConfigCheckRunName,
+		HeadSHA:    sha,
+		Conclusion: report.ConclusionSuccess,
+		Title:      config.RepoFileName + " is valid",
+		Summary:    "The repository configuration applies to this commit's analysis.",
+	}
+
+	if len(problems) > 0 {
+		check.Conclusion = report.ConclusionFailure
+		check.Title = fmt.Sprintf("%d problem(s) in %s", len(problems), config.RepoFileName)
+		check.Summary = "The file was ignored; service defaults apply until it is fixed.\n\n- " +
+			strings.Join(problems, "\n- ")
+	}
+
+	_, err = s.githubClient().CreateCheckRun(ctx, owner, name, check)
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/github"
+	"regression-ci/internal/regression"
+	"regression-ci/internal/report"
+	"regression-ci/pkg/types"
+)
+
+func (s *Server) publishReport(ctx context.Context, repo string, prNumber int) error {
+	if !s.githubClient().Enabled() {
+		return nil
+	}
+
+	owner, name, err := github.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	stored, resp, err := s.detector.PRReport(repo, prNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := s.evaluatePR(resp); err != nil {
+		return err
+	}
+
+	if err := s.syncRegressionLabel(ctx, resp); err != nil {
+		log.Warn().Err(err).Str("repo", repo).Int("pr", prNumber).Msg("failed to sync regression label")
+	}
+
+	body := report.Markdown(resp)
+	if err := s.githubClient().UpsertPRComment(ctx, owner, name, prNumber, report.Marker, body); err != nil {
+		return err
+	}
+
+	check := github.CheckRunResult{
+		HeadSHA:    stored.HeadSHA,
+		Conclusion: report.Conclusion(resp),
+		Title:      report.Title(resp),
+		Summary:    body,
+	}
+
+	if stored.CheckRunID != 0 {
+		return s.githubClient().UpdateCheckRun(ctx, owner, name, stored.CheckRunID, check)
+	}
+
+	checkRunID, err := s.githubClient().CreateCheckRun(ctx, owner, name, check)
+	if err != nil {
+		return err
+	}
+
+	if err := s.detector.SetCheckRunID(repo, prNumber, checkRunID); err != nil {
+		return fmt.Errorf("check run %d created but not recorded: %w", checkRunID, err)
+	}
+
+	return nil
+}
+
+func (s *Server) recordPRAnalysis(ctx context.Context, result *types.AnalyzeResponse) {
+	if err := s.detector.SavePRReport(result); err != nil {
+		log.Error().Err(err).Str("repo", result.Repo).Int("pr", result.PRNumber).Msg("failed to save PR report")
+		return
+	}
+
+	if err := s.evaluatePR(result); err != nil {
+		log.Warn().Err(err).Str("repo", result.Repo).Int("pr", result.PRNumber).Msg("failed to evaluate PR policy")
+	}
+
+	if err := s.publishReport(ctx, result.Repo, result.PRNumber); err != nil {
+		log.Warn().Err(err).Str("repo", result.Repo).Int("pr", result.PRNumber).Msg("failed to publish PR report")
+	}
+}
+
+func (s *Server) evaluatePR(resp *types.AnalyzeResponse) error {
+	policy, err := s.detector.PRPolicy(resp.Repo, resp.PRNumber)
+	if err != nil {
+		return err
+	}
+	s.detector.ApplyPolicy(resp, policy)
+
+	acks, err := s.detector.Acknowledgements(resp.Repo, resp.PRNumber)
+	if err != nil {
+		return err
+	}
+	regression.ApplyAcknowledgements(resp, acks)
+
+	return nil
+}
+
+func (s *Server) syncRegressionLabel(ctx context.Context, resp *types.AnalyzeResponse) error {
+	owner, name, err := github.SplitRepo(resp.Repo)
+	if err != nil {
+		return err
+	}
+
+	labels, err := s.detector.PRLabels(resp.Repo, resp.PRNumber)
+	if err != nil {
+		return err
+	}
+
+	labelled := false
+	for _, label := range labels {
+		if label == regression.LabelRegression {
+			labelled = true
+		}
+	}
+
+	// Only regressions nobody has accepted keep the label on the PR.
+	found := false
+	if resp.Policy == nil || !resp.Policy.Ignore {
+		open, _ := report.Regressions(resp)
+		found = open > 0
+	}
+
+	switch {
+	case found && !labelled:
+		if err := s.githubClient().AddLabel(ctx, owner, name, resp.PRNumber, regression.LabelRegression); err != nil {
+			return err
+		}
+		labels = append(labels, regression.LabelRegression)
+	case !found && labelled:
+		if err := s.githubClient().RemoveLabel(ctx, owner, name, resp.PRNumber, regression.LabelRegression); err != nil {
+			return err
+		}
+		kept := labels[:0]
+		for _, label := range labels {
+			if label != regression.LabelRegression {
+				kept = append(kept, label)
+			}
+		}
+		labels = kept
+	default:
+		return nil
+	}
+
+	return s.detector.SetPRLabels(resp.Repo, resp.PRNumber, labels)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/regression"
+)
+
+func (s *Server) getRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid run id",
+		})
+		return
+	}
+
+	run, err := s.detector.Run(id)
+	if errors.Is(err, regression.ErrRunNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "run not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Int64("run_id", id).Msg("run retrieval failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "run retrieval failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// getCommitAnalysis returns a commit's latest verdicts. For a PR run they
+// are evaluated the way the PR report is, under the PR's label policy and
+// its /perf acknowledgements, so callers see what is actually open.
+func (s *Server) getCommitAnalysis(c *gin.Context) {
+	analysis, err := s.detector.CommitAnalysis(c.Param("repo"), c.Param("sha"))
+	if errors.Is(err, regression.ErrAnalysisNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "analysis not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("repo", c.Param("repo")).Msg("analysis retrieval failed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "analysis retrieval failed",
+		})
+		return
+	}
+
+	if analysis.PRNumber > 0 {
+		if err := s.evaluatePR(analysis); err != nil {
+			log.Error().Err(err).Str("repo", c.Param("repo")).Int("pr", analysis.PRNumber).Msg("PR evaluation failed")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "analysis retrieval failed",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
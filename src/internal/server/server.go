@@ -5,34 +5,40 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
 
 	"regression-ci/internal/config"
 	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
 	"regression-ci/internal/github"
 )
 
 type Server struct {
-	db       *sqlx.DB
-	config   *config.Config
+	store    storage.Store
+	config   atomic.Pointer[config.Config]
 	detector *regression.Detector
-	github   *github.Client
+	github   atomic.Pointer[github.Client]
 	router   *gin.Engine
 	server   *http.Server
+
+	stopMaintenance context.CancelFunc
+	backupMu        sync.Mutex
 }
 
-func New(db *sqlx.DB, cfg *config.Config) *Server {
+func New(store storage.Store, cfg *config.Config) *Server {
 	s := &Server{
-		db:       db,
-		config:   cfg,
-		detector: regression.New(db, cfg.Detection),
-		github:   github.New(cfg.GitHub),
+		store:    store,
+		detector: regression.New(store, cfg.Detection),
 	}
+	s.config.Store(cfg)
+	s.github.Store(github.New(cfg.GitHub))
 
 	s.setupRoutes()
 	s.server = &http.Server{
@@ -45,24 +51,107 @@ func New(db *sqlx.DB, cfg *config.Config) *Server {
 	return s
 }
 
+func (s *Server) currentConfig() *config.Config {
+	return s.config.Load()
+}
+
+func (s *Server) githubClient() *github.Client {
+	return s.github.Load()
+}
+
+// Config returns the configuration in effect.
+func (s *Server) Config() *config.Config {
+	return s.currentConfig()
+}
+
+// ApplyConfig swaps in a reloaded configuration and returns what changed.
+// Detection thresholds, GitHub credentials and the log level take effect
+// immediately; server and database settings are reported but keep their
+// running values until restart. An invalid configuration changes nothing.
+func (s *Server) ApplyConfig(cfg *config.Config) ([]config.Change, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	old := s.currentConfig()
+	changes := config.Diff(old, cfg)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	applied := *cfg
+	applied.Server = old.Server
+	applied.Database = old.Database
+
+	if err := config.ApplyLogLevel(applied.Logging.Level); err != nil {
+		return nil, fmt.Errorf("failed to apply log level: %w", err)
+	}
+	s.detector.SetConfig(applied.Detection)
+	if applied.GitHub != old.GitHub {
+		s.github.Store(github.New(applied.GitHub))
+	}
+	s.config.Store(&applied)
+
+	for _, change := range changes {
+		event := log.Info()
+		if change.Restart {
+			event = log.Warn().Bool("restart_required", true)
+		}
+		event.Str("key", change.Key).Str("old", change.Old).Str("new", change.New).Msg("configuration changed")
+	}
+
+	return changes, nil
+}
+
 func (s *Server) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopMaintenance = cancel
+	go s.runMaintenance(ctx)
+	go s.runBackups(ctx)
+
 	return s.server.ListenAndServe()
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stopMaintenance != nil {
+		s.stopMaintenance()
+	}
 	return s.server.Shutdown(ctx)
 }
 
 func (s *Server) setupRoutes() {
 	s.router = gin.New()
+	s.router.UseRawPath = true
 	s.router.Use(gin.Recovery())
 	s.router.Use(s.loggingMiddleware())
 
 	s.router.GET("/health", s.healthCheck)
 	s.router.POST("/webhook", s.handleWebhook)
-	s.router.POST("/analyze", s.analyzeEndpoint)
+	s.router.POST("/analyze", s.decodeBody(), s.analyzeEndpoint)
+	s.router.POST("/ingest", s.decodeBody(), s.ingestEndpoint)
+	s.router.GET("/formats", s.listFormats)
 	s.router.GET("/config/:repo", s.getRepoConfig)
 	s.router.PUT("/config/:repo", s.updateRepoConfig)
+	s.router.GET("/runs/:id", s.getRun)
+	s.router.GET("/runs/:id/artifacts/:name", s.getArtifact)
+
+	s.router.GET("/repos/:repo/bisections", s.listBisections)
+	s.router.GET("/repos/:repo/commits/:sha/analysis", s.getCommitAnalysis)
+	s.router.GET("/repos/:repo/baselines/:component", s.getBaseline)
+
+	admin := s.router.Group("/admin", s.requireAdmin())
+	admin.POST("/backup", s.createBackup)
+	admin.GET("/repos/:repo/export", s.exportRepo)
+	admin.POST("/import", s.importRepo)
+	admin.POST("/vacuum", s.vacuum)
+	admin.GET("/repos", s.listRepos)
+	admin.GET("/repos/:repo/series", s.listSeries)
+	admin.GET("/repos/:repo/history/:component", s.seriesHistory)
+	admin.POST("/repos/:repo/commits/:sha/reanalyze", s.reanalyzeCommit)
+	admin.DELETE("/runs/:id", s.deleteRun)
+	admin.POST("/repos/:repo/baselines/rebuild", s.rebuildBaselines)
+	admin.DELETE("/repos/:repo/baselines/:component", s.resetBaseline)
+	admin.POST("/repos/:repo/baselines/:component/pin", s.pinBaseline)
 }
 
 func (s *Server) loggingMiddleware() gin.HandlerFunc {
@@ -0,0 +1,116 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decodeBody bounds an upload to ingest.max_body_bytes and undoes its
+// Content-Encoding, so handlers read plain content of bounded size. The
+// limit applies before and after decoding, which keeps a small compressed
+// body from expanding without bound.
+func (s *Server) decodeBody() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := s.currentConfig().Ingest.MaxBodyBytes
+		if c.Request.ContentLength > limit {
+			bodyTooLarge(c, limit)
+			c.Abort()
+			return
+		}
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		var decoded io.ReadCloser
+		switch encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); encoding {
+		case "", "identity":
+			decoded = body
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(body)
+			if err != nil {
+				if !isTooLarge(c, err) {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error": "invalid gzip body",
+					})
+				}
+				c.Abort()
+				return
+			}
+			decoded = gz
+		case "zstd":
+			// No frame of an accepted body needs a window beyond the limit,
+			// so a header declaring one is refused before it is allocated.
+			window := uint64(limit)
+			if window < zstd.MinWindowSize {
+				window = zstd.MinWindowSize
+			}
+			zr, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1),
+				zstd.WithDecoderMaxWindow(window), zstd.WithDecoderMaxMemory(window))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "invalid zstd body",
+				})
+				c.Abort()
+				return
+			}
+			decoded = zstdBody{decoder: zr, limit: limit}
+		default:
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"error": fmt.Sprintf("unsupported Content-Encoding %q; use gzip or zstd", encoding),
+			})
+			c.Abort()
+			return
+		}
+		defer decoded.Close()
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, decoded, limit)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.ContentLength = -1
+
+		c.Next()
+	}
+}
+
+// zstdBody reports a frame too large for the decoder limits as the body
+// limit being exceeded, so handlers answer it like any oversized body.
+type zstdBody struct {
+	decoder *zstd.Decoder
+	limit   int64
+}
+
+func (z zstdBody) Read(p []byte) (int, error) {
+	n, err := z.decoder.Read(p)
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		err = &http.MaxBytesError{Limit: z.limit}
+	}
+	return n, err
+}
+
+func (z zstdBody) Close() error {
+	z.decoder.Close()
+	return nil
+}
+
+// isTooLarge answers 413 when err comes from reading past the body limit.
+func isTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	bodyTooLarge(c, tooLarge.Limit)
+	return true
+}
+
+func bodyTooLarge(c *gin.Context, limit int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": fmt.Sprintf("request body exceeds %d bytes", limit),
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"regression-ci/internal/github"
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+const rerunEventType = "regression-ci-rerun"
+
+func (s *Server) handleWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "failed to read payload",
+		})
+		return
+	}
+
+	if !s.githubClient().ValidateWebhookSignature(payload, c.GetHeader("X-Hub-Signature-256")) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid webhook signature",
+		})
+		return
+	}
+
+	eventType := c.GetHeader("X-GitHub-Event")
+	if eventType == "ping" {
+		c.JSON(http.StatusOK, gin.H{"status": "pong"})
+		return
+	}
+
+	event, err := github.ParseEvent(eventType, payload)
+	if errors.Is(err, github.ErrUnsupportedEvent) {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	switch e := event.(type) {
+	case *github.PushEvent:
+		s.handlePushEvent(c, e)
+	case *github.PullRequestEvent:
+		s.handlePullRequestEvent(c, e)
+	case *github.CommentEvent:
+		s.handleCommentEvent(c, e)
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+	}
+}
+
+func (s *Server) handlePullRequestEvent(c *gin.Context, event *github.PullRequestEvent) {
+	switch event.Action {
+	case "opened", "reopened", "synchronize", "edited", "labeled", "unlabeled":
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+
+	if err := s.detector.SetPRLabels(event.Repo, event.PRNumber, event.Labels); err != nil {
+		log.Error().Err(err).Str("repo", event.Repo).Int("pr", event.PRNumber).Msg("failed to store PR labels")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to store PR labels",
+		})
+		return
+	}
+
+	if event.Action != "labeled" && event.Action != "unlabeled" && event.Action != "edited" {
+		origin := regression.RepoFileOrigin{Commit: event.HeadSHA, Source: regression.RepoFileSourcePullRequest}
+		s.handleRepoFileChange(c.Request.Context(), event.Repo, origin, true)
+	}
+
+	policyChanged := (event.Action == "labeled" || event.Action == "unlabeled") && regression.IsPolicyLabel(event.Label)
+	if policyChanged {
+		if _, _, err := s.detector.PRReport(event.Repo, event.PRNumber); err == nil {
+			if err := s.publishReport(c.Request.Context(), event.Repo, event.PRNumber); err != nil {
+				log.Warn().Err(err).Str("repo", event.Repo).Int("pr", event.PRNumber).Msg("failed to refresh PR report")
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "processed",
+		"labels": event.Labels,
+		"policy": regression.PolicyFromLabels(event.Labels),
+	})
+}
+
+func (s *Server) handlePushEvent(c *gin.Context, event *github.PushEvent) {
+	if event.Deleted || !strings.HasPrefix(event.Ref, "refs/heads/") {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+
+	branch := strings.TrimPrefix(event.Ref, "refs/heads/")
+	origin := regression.RepoFileOrigin{
+		Commit:        event.HeadSHA,
+		Branch:        branch,
+		DefaultBranch: branch == event.DefaultBranch,
+		Source:        regression.RepoFileSourcePush,
+	}
+	s.handleRepoFileChange(c.Request.Context(), event.Repo, origin, false)
+
+	c.JSON(http.StatusOK, gin.H{"status": "processed"})
+}
+
+func (s *Server) handleRepoFileChange(ctx context.Context, repo string, origin regression.RepoFileOrigin, reportCheck bool) {
+	if !s.githubClient().Enabled() {
+		return
+	}
+
+	sha := origin.Commit
+	present, problems, err := s.syncRepoFile(ctx, repo, origin)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repo).Str("commit", sha).Msg("failed to sync repository config")
+		return
+	}
+
+	if len(problems) > 0 {
+		log.Warn().Str("repo", repo).Str("commit", sha).Strs("problems", problems).Msg("invalid repository config")
+	}
+
+	if reportCheck && present {
+		if err := s.reportRepoFile(ctx, repo, sha, problems); err != nil {
+			log.Warn().Err(err).Str("repo", repo).Str("commit", sha).Msg("failed to report repository config check")
+		}
+	}
+}
+
+func (s *Server) handleCommentEvent(c *gin.Context, event *github.CommentEvent) {
+	commands, parseErrs := github.ParseCommands(event.Body)
+	if event.Action != "created" || (len(commands) == 0 && len(parseErrs) == 0) {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	owner, name, err := github.SplitRepo(event.Repo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	allowed, err := s.githubClient().CanWrite(ctx, owner, name, event.Author)
+	if err != nil {
+		log.Error().Err(err).Str("repo", event.Repo).Str("user", event.Author).Msg("permission check failed")
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "permission check failed",
+		})
+		return
+	}
+
+	if !allowed {
+		s.replyToCommand(ctx, event, fmt.Sprintf("@%s only collaborators with write access can run `/perf` commands.", event.Author))
+		c.JSON(http.StatusOK, gin.H{
+			"status": "denied",
+		})
+		return
+	}
+
+	if len(parseErrs) > 0 {
+		problems := make([]string, len(parseErrs))
+		for i, parseErr := range parseErrs {
+			problems[i] = "- " + parseErr.Error()
+		}
+		s.replyToCommand(ctx, event, fmt.Sprintf("@%s some commands could not be understood:\n%s", event.Author, strings.Join(problems, "\n")))
+	}
+
+	var executed []string
+	for _, cmd := range commands {
+		err := s.runCommand(ctx, event, cmd)
+		var rejected *commandRejection
+		if errors.As(err, &rejected) {
+			s.replyToCommand(ctx, event, fmt.Sprintf("@%s %s", event.Author, rejected.reason))
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("repo", event.Repo).Int("pr", event.PRNumber).Str("command", cmd.Name).Msg("command failed")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("command %s failed", cmd.Name),
+			})
+			return
+		}
+		executed = append(executed, cmd.Name)
+	}
+
+	if err := s.publishReport(ctx, event.Repo, event.PRNumber); err != nil {
+		log.Warn().Err(err).Str("repo", event.Repo).Int("pr", event.PRNumber).Msg("failed to refresh PR report")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "processed",
+		"commands": executed,
+	})
+}
+
+// commandRejection is a command that was understood but cannot apply; the
+// author gets the reason as a reply instead of the webhook failing.
+type commandRejection struct {
+	reason string
+}
+
+func (r *commandRejection) Error() string {
+	return r.reason
+}
+
+func (s *Server) runCommand(ctx context.Context, event *github.CommentEvent, cmd github.Command) error {
+	switch cmd.Name {
+	case github.CommandAccept, github.CommandIgnore:
+		stored, resp, err := s.detector.PRReport(event.Repo, event.PRNumber)
+		if err != nil {
+			return &commandRejection{reason: fmt.Sprintf("there is no analysis of this PR to `/perf %s` yet.", cmd.Name)}
+		}
+
+		ack := types.Acknowledgement{
+			Repo:      event.Repo,
+			PRNumber:  event.PRNumber,
+			HeadSHA:   stored.HeadSHA,
+			Component: cmd.Component,
+			Action:    regression.AckActionAccept,
+			Author:    event.Author,
+			Reason:    cmd.Reason,
+		}
+		if cmd.Name == github.CommandIgnore {
+			ack.Component = regression.AllComponents
+			ack.Action = regression.AckActionIgnore
+		} else if !hasComponent(resp, cmd.Component) {
+			return &commandRejection{reason: fmt.Sprintf("`%s` is not in the latest analysis of this PR, so it cannot be accepted.",
+				cmd.Component)}
+		}
+		return s.detector.Acknowledge(ack)
+
+	case github.CommandRerun:
+		owner, name, err := github.SplitRepo(event.Repo)
+		if err != nil {
+			return err
+		}
+
+		payload := map[string]interface{}{
+			"pr_number":    event.PRNumber,
+			"requested_by": event.Author,
+		}
+		if stored, _, err := s.detector.PRReport(event.Repo, event.PRNumber); err == nil {
+			payload["sha"] = stored.HeadSHA
+		}
+		return s.githubClient().Dispatch(ctx, owner, name, rerunEventType, payload)
+	}
+
+	return fmt.Errorf("unhandled command %s", cmd.Name)
+}
+
+func (s *Server) replyToCommand(ctx context.Context, event *github.CommentEvent, body string) {
+	owner, name, err := github.SplitRepo(event.Repo)
+	if err != nil {
+		return
+	}
+
+	if err := s.githubClient().CreatePRComment(ctx, owner, name, event.PRNumber, body); err != nil {
+		log.Warn().Err(err).Str("repo", event.Repo).Int("pr", event.PRNumber).Msg("failed to reply to command")
+	}
+}
+
+func hasComponent(resp *types.AnalyzeResponse, component string) bool {
+	for _, c := range resp.Components {
+		if c.Component == component {
+			return true
+		}
+	}
+	return false
+}
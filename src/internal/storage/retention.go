@@ -0,0 +1,202 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gonum.org/v1/gonum/stat"
+
+	"regression-ci/internal/database"
+	"regression-ci/pkg/types"
+)
+
+const (
+	GranularityCommit = "commit"
+	GranularityDay    = "day"
+)
+
+type DownsampleStats struct {
+	SamplesRemoved    int `json:"samples_removed"`
+	AggregatesWritten int `json:"aggregates_written"`
+}
+
+type aggregate struct {
+	repo, branch, component, metric, bucket, commit string
+	values                                          []float64
+	first, last                                     int64
+}
+
+// downsampleBatchSize bounds how many expired samples one transaction
+// folds, so a large backlog never holds the write lock for long.
+const downsampleBatchSize = 1000
+
+// DownsampleBenchmarks folds raw samples older than before into one
+// aggregate per bucket and deletes them. Samples backing a current baseline
+// are left alone so its provenance stays intact, and samples of bisection
+// runs stay with their run, since they are not part of the history.
+func (s *SQLStore) DownsampleBenchmarks(before int64, granularity string) (DownsampleStats, error) {
+	var stats DownsampleStats
+	if granularity != GranularityCommit && granularity != GranularityDay {
+		return stats, fmt.Errorf("unknown granularity %q", granularity)
+	}
+
+	var afterID int64
+	for {
+		batch, lastID, err := s.downsampleBatch(before, granularity, afterID)
+		if err != nil {
+			return stats, err
+		}
+		if lastID == afterID {
+			return stats, nil
+		}
+		stats.SamplesRemoved += batch.SamplesRemoved
+		stats.AggregatesWritten += batch.AggregatesWritten
+		afterID = lastID
+	}
+}
+
+// downsampleBatch folds the next batch of expired samples with ids above
+// afterID and returns the last id it handled.
+func (s *SQLStore) downsampleBatch(before int64, granularity string, afterID int64) (DownsampleStats, int64, error) {
+	var stats DownsampleStats
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return stats, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var samples []types.Benchmark
+	query := `SELECT ` + benchmarkColumns + ` FROM benchmarks
+	          WHERE id > ? AND timestamp < ? AND id NOT IN (SELECT benchmark_id FROM baseline_samples)
+	            AND NOT EXISTS (SELECT 1 FROM runs r WHERE r.id = benchmarks.run_id AND r.bisection_id != 0)
+	          ORDER BY id LIMIT ?`
+	if err := tx.Select(&samples, tx.Rebind(query), afterID, before, downsampleBatchSize); err != nil {
+		return stats, 0, fmt.Errorf("failed to load expired samples: %w", err)
+	}
+	if len(samples) == 0 {
+		return stats, afterID, nil
+	}
+
+	buckets := make(map[string]*aggregate)
+	var order []string
+	ids := make([]int64, len(samples))
+	for i, sample := range samples {
+		ids[i] = sample.ID
+		bucket := sample.CommitHash
+		if granularity == GranularityDay {
+			bucket = time.Unix(sample.Timestamp, 0).UTC().Format("2006-01-02")
+		}
+
+		key := strings.Join([]string{sample.Repo, sample.Branch, sample.Component, sample.Metric, bucket}, "\x00")
+		agg, ok := buckets[key]
+		if !ok {
+			agg = &aggregate{repo: sample.Repo, branch: sample.Branch, component: sample.Component,
+				metric: sample.Metric, bucket: bucket, commit: sample.CommitHash,
+				first: sample.Timestamp, last: sample.Timestamp}
+			buckets[key] = agg
+			order = append(order, key)
+		}
+		agg.values = append(agg.values, sample.Value)
+		if sample.Timestamp < agg.first {
+			agg.first = sample.Timestamp
+		}
+		if sample.Timestamp >= agg.last {
+			agg.commit = sample.CommitHash
+			agg.last = sample.Timestamp
+		}
+	}
+
+	for _, key := range order {
+		if err := saveAggregate(tx, buckets[key]); err != nil {
+			return stats, 0, err
+		}
+		stats.AggregatesWritten++
+	}
+
+	deleteQuery, args, err := sqlx.In(`DELETE FROM benchmarks WHERE id IN (?)`, ids)
+	if err != nil {
+		return stats, 0, fmt.Errorf("failed to build delete: %w", err)
+	}
+	result, err := tx.Exec(tx.Rebind(deleteQuery), args...)
+	if err != nil {
+		return stats, 0, fmt.Errorf("failed to delete expired samples: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return stats, 0, fmt.Errorf("failed to delete expired samples: %w", err)
+	}
+	stats.SamplesRemoved = int(removed)
+
+	if err := tx.Commit(); err != nil {
+		return DownsampleStats{}, 0, err
+	}
+
+	return stats, ids[len(ids)-1], nil
+}
+
+// saveAggregate merges a bucket into any aggregate already stored for it.
+// Min, max and count combine exactly; the median of the larger side wins,
+// since the raw values behind the stored median are gone.
+func saveAggregate(tx *sqlx.Tx, agg *aggregate) error {
+	sort.Float64s(agg.values)
+	median := stat.Quantile(0.5, stat.Empirical, agg.values, nil)
+
+	query := `INSERT INTO benchmark_aggregates (repo, branch, component, metric, bucket, commit_hash,
+	          min_value, median_value, max_value, sample_count, first_timestamp, last_timestamp)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	          ON CONFLICT (repo, branch, component, metric, bucket) DO UPDATE SET
+	              commit_hash = CASE WHEN excluded.last_timestamp >= benchmark_aggregates.last_timestamp
+	                                 THEN excluded.commit_hash ELSE benchmark_aggregates.commit_hash END,
+	              min_value = CASE WHEN excluded.min_value < benchmark_aggregates.min_value
+	                               THEN excluded.min_value ELSE benchmark_aggregates.min_value END,
+	              max_value = CASE WHEN excluded.max_value > benchmark_aggregates.max_value
+	                               THEN excluded.max_value ELSE benchmark_aggregates.max_value END,
+	              median_value = CASE WHEN excluded.sample_count > benchmark_aggregates.sample_count
+	                                  THEN excluded.median_value ELSE benchmark_aggregates.median_value END,
+	              sample_count = benchmark_aggregates.sample_count + excluded.sample_count,
+	              first_timestamp = CASE WHEN excluded.first_timestamp < benchmark_aggregates.first_timestamp
+	                                     THEN excluded.first_timestamp ELSE benchmark_aggregates.first_timestamp END,
+	              last_timestamp = CASE WHEN excluded.last_timestamp > benchmark_aggregates.last_timestamp
+	                                    THEN excluded.last_timestamp ELSE benchmark_aggregates.last_timestamp END`
+	_, err := tx.Exec(tx.Rebind(query), agg.repo, agg.branch, agg.component, agg.metric, agg.bucket, agg.commit,
+		agg.values[0], median, agg.values[len(agg.values)-1], len(agg.values), agg.first, agg.last)
+	if err != nil {
+		return fmt.Errorf("failed to save aggregate: %w", err)
+	}
+
+	return nil
+}
+
+// Vacuum returns space freed by downsampling to the operating system.
+func (s *SQLStore) Vacuum() error {
+	statement := "VACUUM"
+	if s.db.DriverName() == database.DriverPostgres {
+		statement = "VACUUM ANALYZE benchmarks"
+	}
+
+	if _, err := s.db.Exec(statement); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	return nil
+}
+
+// Backup writes a consistent copy of a SQLite database to path while the
+// service keeps running. PostgreSQL deployments should use pg_dump instead.
+func (s *SQLStore) Backup(path string) error {
+	if s.db.DriverName() != database.DriverSQLite {
+		return ErrBackupUnsupported
+	}
+
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	return nil
+}
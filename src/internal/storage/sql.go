@@ -0,0 +1,750 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package storage
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"regression-ci/pkg/types"
+)
+
+const (
+	benchmarkColumns = `id, repo, branch, commit_hash, component, metric, value, timestamp,
+	                    COALESCE(run_id, 0) AS run_id`
+
+	runColumns = `id, uid, repo, branch, commit_hash, pr_number, ci_url, runner, started_at, finished_at,
+	              metadata, bisection_id`
+
+	analysisColumns = `id, run_id, repo, commit_hash, pr_number, component, metric, unit, direction,
+	                   is_regression, current_value, baseline_value, percent_change, absolute_change,
+	                   threshold_mode, threshold, confidence_score, sample_size, p_value, baseline_source,
+	                   baseline_commit, error, created_at`
+
+	repoFileColumns = `repo, commit_hash, branch, default_branch, source, content, problems, fetched_at`
+
+	artifactColumns = `id, run_id, repo, name, content_type, component, kind, size, sha256, created_at`
+
+	bisectionColumns = `id, repo, branch, component, metric, good_commit, bad_commit, good_value, bad_value,
+	                    candidates, low, high, pending_commit, status, culprit, created_at, updated_at`
+)
+
+// SQLStore implements Store for both SQLite and PostgreSQL. Queries are
+// written with ? placeholders and standard ON CONFLICT upserts, which both
+// engines understand once rebound for the connection's driver.
+type SQLStore struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) Ping() error {
+	return s.db.Ping()
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLStore) get(dest interface{}, query string, args ...interface{}) error {
+	err := s.db.Get(dest, s.db.Rebind(query), args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *SQLStore) sel(dest interface{}, query string, args ...interface{}) error {
+	return s.db.Select(dest, s.db.Rebind(query), args...)
+}
+
+func (s *SQLStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.db.Exec(s.db.Rebind(query), args...)
+}
+
+func (s *SQLStore) InsertBenchmarks(benchmarks []types.Benchmark) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertBenchmarks(tx, benchmarks); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertBenchmarks(tx *sqlx.Tx, benchmarks []types.Benchmark) error {
+	query := tx.Rebind(`INSERT INTO benchmarks (repo, branch, commit_hash, component, metric, value, timestamp, run_id)
+	                    VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	for _, b := range benchmarks {
+		if b.Metric == "" {
+			b.Metric = types.DefaultMetric
+		}
+		runID := sql.NullInt64{Int64: b.RunID, Valid: b.RunID != 0}
+		_, err := tx.Exec(query, b.Repo, b.Branch, b.CommitHash, b.Component, b.Metric, b.Value, b.Timestamp, runID)
+		if err != nil {
+			return fmt.Errorf("failed to insert benchmark: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLStore) RecentBenchmarks(repo, component, metric string, limit int) ([]types.Benchmark, error) {
+	var samples []types.Benchmark
+	query := `SELECT ` + benchmarkColumns + ` FROM benchmark_history
+	          WHERE repo = ? AND component = ? AND metric = ?
+	          ORDER BY timestamp DESC, id DESC LIMIT ?`
+	if err := s.sel(&samples, query, repo, component, metric, limit); err != nil {
+		return nil, fmt.Errorf("failed to load benchmarks: %w", err)
+	}
+
+	return samples, nil
+}
+
+func (s *SQLStore) CommitBenchmarks(repo, component, metric, commit string) ([]types.Benchmark, error) {
+	var samples []types.Benchmark
+	query := `SELECT ` + benchmarkColumns + ` FROM benchmark_history
+	          WHERE repo = ? AND component = ? AND metric = ? AND commit_hash = ?
+	          ORDER BY timestamp DESC, id DESC`
+	if err := s.sel(&samples, query, repo, component, metric, commit); err != nil {
+		return nil, fmt.Errorf("failed to load commit benchmarks: %w", err)
+	}
+
+	return samples, nil
+}
+
+func (s *SQLStore) LastBenchmarkBefore(repo, branch, component, metric, commit string) (*types.Benchmark, error) {
+	var sample types.Benchmark
+	query := `SELECT ` + benchmarkColumns + ` FROM benchmark_history
+	          WHERE repo = ? AND branch = ? AND component = ? AND metric = ? AND commit_hash != ?
+	          ORDER BY timestamp DESC, id DESC LIMIT 1`
+	if err := s.get(&sample, query, repo, branch, component, metric, commit); err != nil {
+		return nil, err
+	}
+
+	return &sample, nil
+}
+
+func (s *SQLStore) BenchmarkSeries(repo string) ([]Series, error) {
+	var series []Series
+	query := `SELECT DISTINCT component, metric FROM benchmark_history WHERE repo = ? ORDER BY component, metric`
+	if err := s.sel(&series, query, repo); err != nil {
+		return nil, fmt.Errorf("failed to list series: %w", err)
+	}
+
+	return series, nil
+}
+
+// Repos lists every repository with runs or history. Runs predating the
+// runs table only show up as series.
+func (s *SQLStore) Repos() ([]types.RepoSummary, error) {
+	repos := []types.RepoSummary{}
+	query := `SELECT repo, CAST(SUM(runs) AS BIGINT) AS runs, CAST(SUM(series) AS BIGINT) AS series,
+	                 MAX(last_run_at) AS last_run_at
+	          FROM (
+	              SELECT repo, COUNT(*) AS runs, 0 AS series, MAX(finished_at) AS last_run_at
+	              FROM runs GROUP BY repo
+	              UNION ALL
+	              SELECT repo, 0, COUNT(*), 0
+	              FROM (SELECT DISTINCT repo, component, metric FROM benchmark_history) s GROUP BY repo
+	          ) r
+	          GROUP BY repo ORDER BY repo`
+	if err := s.sel(&repos, query); err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	return repos, nil
+}
+
+func (s *SQLStore) SeriesSummaries(repo string) ([]types.SeriesSummary, error) {
+	series := []types.SeriesSummary{}
+	query := `SELECT h.component, h.metric, COUNT(*) AS samples, MAX(h.timestamp) AS last_timestamp,
+	                 b.baseline_value, COALESCE(bs.source, '') AS baseline_source
+	          FROM benchmark_history h
+	          LEFT JOIN baselines b ON b.repo = h.repo AND b.component = h.component AND b.metric = h.metric
+	          LEFT JOIN baseline_sources bs
+	              ON bs.repo = h.repo AND bs.component = h.component AND bs.metric = h.metric
+	          WHERE h.repo = ?
+	          GROUP BY h.component, h.metric, b.baseline_value, bs.source
+	          ORDER BY h.component, h.metric`
+	if err := s.sel(&series, query, repo); err != nil {
+		return nil, fmt.Errorf("failed to summarize series: %w", err)
+	}
+
+	return series, nil
+}
+
+func (s *SQLStore) Baseline(repo, component, metric string) (*types.Baseline, error) {
+	var baseline types.Baseline
+	query := `SELECT repo, component, metric, baseline_value, sample_count, updated_at
+	          FROM baselines WHERE repo = ? AND component = ? AND metric = ?`
+	if err := s.get(&baseline, query, repo, component, metric); err != nil {
+		return nil, err
+	}
+
+	return &baseline, nil
+}
+
+func (s *SQLStore) BaselineSource(repo, component, metric string) (*BaselineSource, error) {
+	var source BaselineSource
+	query := `SELECT source, estimator, pinned_commit FROM baseline_sources
+	          WHERE repo = ? AND component = ? AND metric = ?`
+	if err := s.get(&source, query, repo, component, metric); err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+func (s *SQLStore) BaselineSamples(repo, component, metric string) ([]types.Benchmark, error) {
+	samples := []types.Benchmark{}
+	query := `SELECT b.id, b.repo, b.branch, b.commit_hash, b.component, b.metric, b.value, b.timestamp,
+	                 COALESCE(b.run_id, 0) AS run_id
+	          FROM baseline_samples s JOIN benchmarks b ON b.id = s.benchmark_id
+	          WHERE s.repo = ? AND s.component = ? AND s.metric = ?
+	          ORDER BY b.timestamp DESC, b.id DESC`
+	if err := s.sel(&samples, query, repo, component, metric); err != nil {
+		return nil, fmt.Errorf("failed to load baseline samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+func (s *SQLStore) SaveBaseline(baseline types.Baseline, source BaselineSource, samples []types.Benchmark) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if baseline.Metric == "" {
+		baseline.Metric = types.DefaultMetric
+	}
+
+	query := `INSERT INTO baselines (repo, component, metric, baseline_value, sample_count, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?)
+	          ON CONFLICT (repo, component, metric) DO UPDATE SET
+	              baseline_value = excluded.baseline_value,
+	              sample_count = excluded.sample_count,
+	              updated_at = excluded.updated_at`
+	_, err = tx.Exec(tx.Rebind(query), baseline.Repo, baseline.Component, baseline.Metric,
+		baseline.BaselineValue, baseline.SampleCount, baseline.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save baseline: %w", err)
+	}
+
+	query = `INSERT INTO baseline_sources (repo, component, metric, source, estimator, pinned_commit)
+	         VALUES (?, ?, ?, ?, ?, ?)
+	         ON CONFLICT (repo, component, metric) DO UPDATE SET
+	             source = excluded.source,
+	             estimator = excluded.estimator,
+	             pinned_commit = excluded.pinned_commit`
+	_, err = tx.Exec(tx.Rebind(query), baseline.Repo, baseline.Component, baseline.Metric,
+		source.Source, source.Estimator, source.PinnedCommit)
+	if err != nil {
+		return fmt.Errorf("failed to save baseline source: %w", err)
+	}
+
+	query = `DELETE FROM baseline_samples WHERE repo = ? AND component = ? AND metric = ?`
+	if _, err := tx.Exec(tx.Rebind(query), baseline.Repo, baseline.Component, baseline.Metric); err != nil {
+		return fmt.Errorf("failed to clear baseline samples: %w", err)
+	}
+
+	query = tx.Rebind(`INSERT INTO baseline_samples (repo, component, metric, benchmark_id) VALUES (?, ?, ?, ?)
+	                   ON CONFLICT DO NOTHING`)
+	for _, sample := range samples {
+		// Downsampled history has no raw row to point at.
+		if sample.ID <= 0 {
+			continue
+		}
+		if _, err := tx.Exec(query, baseline.Repo, baseline.Component, baseline.Metric, sample.ID); err != nil {
+			return fmt.Errorf("failed to save baseline sample: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) DeleteBaseline(repo, component, metric string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := ` WHERE repo = ? AND component = ? AND metric = ?`
+	res, err := tx.Exec(tx.Rebind(`DELETE FROM baselines`+where), repo, component, metric)
+	if err != nil {
+		return fmt.Errorf("failed to delete baseline: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM baseline_sources`+where), repo, component, metric); err != nil {
+		return fmt.Errorf("failed to delete baseline source: %w", err)
+	}
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM baseline_samples`+where), repo, component, metric); err != nil {
+		return fmt.Errorf("failed to delete baseline samples: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) RepoConfig(repo string) (*types.RepoConfig, error) {
+	var config types.RepoConfig
+	query := `SELECT repo, threshold_percent, min_samples, enabled FROM config WHERE repo = ?`
+	if err := s.get(&config, query, repo); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (s *SQLStore) SaveRepoConfig(config types.RepoConfig) error {
+	query := `INSERT INTO config (repo, threshold_percent, min_samples, enabled)
+	          VALUES (?, ?, ?, ?)
+	          ON CONFLICT (repo) DO UPDATE SET
+	              threshold_percent = excluded.threshold_percent,
+	              min_samples = excluded.min_samples,
+	              enabled = excluded.enabled`
+	_, err := s.exec(query, config.Repo, config.ThresholdPercent, config.MinSamples, config.Enabled)
+	if err != nil {
+		return fmt.Errorf("failed to save repo settings: %w", err)
+	}
+
+	return nil
+}
+
+// SaveRepoFile caches a fetch. Refetching a commit keeps the branch it was
+// first seen on when the new fetch does not know one.
+func (s *SQLStore) SaveRepoFile(file RepoFile) error {
+	query := `INSERT INTO repo_files (repo, commit_hash, branch, default_branch, source, content, problems,
+	          fetched_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	          ON CONFLICT (repo, commit_hash) DO UPDATE SET
+	              branch = CASE WHEN excluded.branch != '' THEN excluded.branch ELSE repo_files.branch END,
+	              default_branch = CASE WHEN excluded.branch != '' THEN excluded.default_branch
+	                                    ELSE repo_files.default_branch END,
+	              source = excluded.source,
+	              content = excluded.content,
+	              problems = excluded.problems,
+	              fetched_at = excluded.fetched_at`
+	_, err := s.exec(query, file.Repo, file.CommitHash, file.Branch, file.DefaultBranch, file.Source, file.Content,
+		file.Problems, file.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save repo config: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) RepoFile(repo, commit string) (*RepoFile, error) {
+	var file RepoFile
+	query := `SELECT ` + repoFileColumns + ` FROM repo_files WHERE repo = ? AND commit_hash = ?`
+	if err := s.get(&file, query, repo, commit); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (s *SQLStore) LatestRepoFile(repo, branch, source string) (*RepoFile, error) {
+	var file RepoFile
+	query := `SELECT ` + repoFileColumns + ` FROM repo_files
+	          WHERE repo = ? AND branch = ? AND source = ?
+	          ORDER BY fetched_at DESC LIMIT 1`
+	if err := s.get(&file, query, repo, branch, source); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (s *SQLStore) LatestDefaultBranchRepoFile(repo, source string) (*RepoFile, error) {
+	var file RepoFile
+	query := `SELECT ` + repoFileColumns + ` FROM repo_files
+	          WHERE repo = ? AND default_branch = ? AND source = ?
+	          ORDER BY fetched_at DESC LIMIT 1`
+	if err := s.get(&file, query, repo, true, source); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// SavePRReport upserts the latest analysis for a PR. The stored check run is
+// kept only while the head commit is unchanged; a new push gets a new run.
+func (s *SQLStore) SavePRReport(report types.PRReport) error {
+	query := `INSERT INTO pr_reports (repo, pr_number, head_sha, check_run_id, response, updated_at)
+	          VALUES (?, ?, ?, 0, ?, ?)
+	          ON CONFLICT (repo, pr_number) DO UPDATE SET
+	              check_run_id = CASE WHEN pr_reports.head_sha = excluded.head_sha
+	                                  THEN pr_reports.check_run_id ELSE 0 END,
+	              head_sha = excluded.head_sha,
+	              response = excluded.response,
+	              updated_at = excluded.updated_at`
+	_, err := s.exec(query, report.Repo, report.PRNumber, report.HeadSHA, report.Response, report.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save PR report: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) PRReport(repo string, prNumber int) (*types.PRReport, error) {
+	var report types.PRReport
+	query := `SELECT repo, pr_number, head_sha, check_run_id, response, updated_at
+	          FROM pr_reports WHERE repo = ? AND pr_number = ?`
+	if err := s.get(&report, query, repo, prNumber); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (s *SQLStore) SetCheckRunID(repo string, prNumber int, checkRunID int64) error {
+	query := `UPDATE pr_reports SET check_run_id = ? WHERE repo = ? AND pr_number = ?`
+	if _, err := s.exec(query, checkRunID, repo, prNumber); err != nil {
+		return fmt.Errorf("failed to save check run id: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) AddAcknowledgement(ack types.Acknowledgement) error {
+	query := `INSERT INTO acknowledgements (repo, pr_number, head_sha, component, action, author, reason,
+	          created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.exec(query, ack.Repo, ack.PRNumber, ack.HeadSHA, ack.Component, ack.Action,
+		ack.Author, ack.Reason, ack.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record acknowledgement: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) Acknowledgements(repo string, prNumber int) ([]types.Acknowledgement, error) {
+	var acks []types.Acknowledgement
+	query := `SELECT id, repo, pr_number, head_sha, component, action, author, reason, created_at
+	          FROM acknowledgements WHERE repo = ? AND pr_number = ?
+	          ORDER BY created_at, id`
+	if err := s.sel(&acks, query, repo, prNumber); err != nil {
+		return nil, fmt.Errorf("failed to load acknowledgements: %w", err)
+	}
+
+	return acks, nil
+}
+
+func (s *SQLStore) SetPRLabels(repo string, prNumber int, labels []string) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM pr_labels WHERE repo = ? AND pr_number = ?`), repo, prNumber); err != nil {
+		return fmt.Errorf("failed to clear PR labels: %w", err)
+	}
+
+	query := tx.Rebind(`INSERT INTO pr_labels (repo, pr_number, label) VALUES (?, ?, ?)
+	                    ON CONFLICT DO NOTHING`)
+	for _, label := range labels {
+		if _, err := tx.Exec(query, repo, prNumber, label); err != nil {
+			return fmt.Errorf("failed to save PR label: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) PRLabels(repo string, prNumber int) ([]string, error) {
+	var labels []string
+	query := `SELECT label FROM pr_labels WHERE repo = ? AND pr_number = ? ORDER BY label`
+	if err := s.sel(&labels, query, repo, prNumber); err != nil {
+		return nil, fmt.Errorf("failed to load PR labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+func (s *SQLStore) CreateBisection(b *types.Bisection) error {
+	if b.Metric == "" {
+		b.Metric = types.DefaultMetric
+	}
+
+	query := `INSERT INTO bisections (repo, branch, component, metric, good_commit, bad_commit, good_value,
+	          bad_value, candidates, low, high, pending_commit, status, culprit, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+	err := s.db.Get(&b.ID, s.db.Rebind(query), b.Repo, b.Branch, b.Component, b.Metric, b.GoodCommit,
+		b.BadCommit, b.GoodValue, b.BadValue, b.Candidates, b.Low, b.High, b.PendingCommit,
+		b.Status, b.Culprit, b.CreatedAt, b.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bisection: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) UpdateBisection(b *types.Bisection) error {
+	query := `UPDATE bisections SET low = ?, high = ?, pending_commit = ?, status = ?, culprit = ?,
+	          updated_at = ? WHERE id = ?`
+	_, err := s.exec(query, b.Low, b.High, b.PendingCommit, b.Status, b.Culprit, b.UpdatedAt, b.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update bisection: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) PendingBisections(repo, status, commit string) ([]types.Bisection, error) {
+	var bisections []types.Bisection
+	query := `SELECT ` + bisectionColumns + ` FROM bisections
+	          WHERE repo = ? AND status = ? AND pending_commit = ?`
+	if err := s.sel(&bisections, query, repo, status, commit); err != nil {
+		return nil, fmt.Errorf("failed to load pending bisections: %w", err)
+	}
+
+	return bisections, nil
+}
+
+func (s *SQLStore) HasBisection(repo, badCommit string) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM bisections WHERE repo = ? AND bad_commit = ?`
+	if err := s.get(&count, query, repo, badCommit); err != nil {
+		return false, fmt.Errorf("failed to look up bisection: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *SQLStore) Bisections(repo string) ([]types.Bisection, error) {
+	bisections := []types.Bisection{}
+	query := `SELECT ` + bisectionColumns + ` FROM bisections
+	          WHERE repo = ? ORDER BY created_at DESC, id DESC`
+	if err := s.sel(&bisections, query, repo); err != nil {
+		return nil, fmt.Errorf("failed to list bisections: %w", err)
+	}
+
+	return bisections, nil
+}
+
+// CreateRun stores a run and its samples in one transaction, assigning the
+// run's ID to every sample.
+func (s *SQLStore) CreateRun(run *types.Run, benchmarks []types.Benchmark) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if run.UID == "" {
+		if run.UID, err = newRunUID(); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO runs (uid, repo, branch, commit_hash, pr_number, ci_url, runner, started_at,
+	          finished_at, metadata, bisection_id)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+	err = tx.Get(&run.ID, tx.Rebind(query), run.UID, run.Repo, run.Branch, run.CommitHash, run.PRNumber,
+		run.CIURL, run.Runner, run.StartedAt, run.FinishedAt, run.Metadata, run.BisectionID)
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+
+	for i := range benchmarks {
+		benchmarks[i].RunID = run.ID
+	}
+	if err := insertBenchmarks(tx, benchmarks); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func newRunUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate run uid: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *SQLStore) Run(id int64) (*types.Run, error) {
+	var run types.Run
+	if err := s.get(&run, `SELECT `+runColumns+` FROM runs WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (s *SQLStore) RunBenchmarks(id int64) ([]types.Benchmark, error) {
+	benchmarks := []types.Benchmark{}
+	query := `SELECT ` + benchmarkColumns + ` FROM benchmarks WHERE run_id = ? ORDER BY component, id`
+	if err := s.sel(&benchmarks, query, id); err != nil {
+		return nil, fmt.Errorf("failed to load run benchmarks: %w", err)
+	}
+
+	return benchmarks, nil
+}
+
+func (s *SQLStore) LatestCommitRun(repo, commit string) (*types.Run, error) {
+	var run types.Run
+	query := `SELECT ` + runColumns + ` FROM runs WHERE repo = ? AND commit_hash = ? AND bisection_id = 0
+	          ORDER BY finished_at DESC, id DESC LIMIT 1`
+	if err := s.get(&run, query, repo, commit); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// DeleteRun removes a run with its samples, verdicts and artifact records.
+// Baselines built from its samples keep their value until rebuilt.
+func (s *SQLStore) DeleteRun(id int64) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(tx.Rebind(`DELETE FROM runs WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	for _, statement := range []string{
+		`DELETE FROM baseline_samples WHERE benchmark_id IN (SELECT id FROM benchmarks WHERE run_id = ?)`,
+		`DELETE FROM benchmarks WHERE run_id = ?`,
+		`DELETE FROM analysis_results WHERE run_id = ?`,
+		`DELETE FROM artifacts WHERE run_id = ?`,
+	} {
+		if _, err := tx.Exec(tx.Rebind(statement), id); err != nil {
+			return fmt.Errorf("failed to delete run: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) SaveArtifacts(artifacts []types.Artifact) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := tx.Rebind(`INSERT INTO artifacts (run_id, repo, name, content_type, component, kind, size, sha256,
+	                    created_at)
+	                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	for _, a := range artifacts {
+		_, err := tx.Exec(query, a.RunID, a.Repo, a.Name, a.ContentType, a.Component, a.Kind, a.Size, a.SHA256,
+			a.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save artifact: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) RunArtifacts(runID int64) ([]types.Artifact, error) {
+	var artifacts []types.Artifact
+	query := `SELECT ` + artifactColumns + ` FROM artifacts WHERE run_id = ? ORDER BY name`
+	if err := s.sel(&artifacts, query, runID); err != nil {
+		return nil, fmt.Errorf("failed to load run artifacts: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+func (s *SQLStore) Artifact(runID int64, name string) (*types.Artifact, error) {
+	var artifact types.Artifact
+	query := `SELECT ` + artifactColumns + ` FROM artifacts WHERE run_id = ? AND name = ?`
+	if err := s.get(&artifact, query, runID, name); err != nil {
+		return nil, err
+	}
+
+	return &artifact, nil
+}
+
+// BaselineProfile finds the profile to compare a run's profile with: the
+// newest earlier one from a run that contributed samples to the series'
+// baseline, or failing that the newest earlier one of the component.
+func (s *SQLStore) BaselineProfile(repo, component, metric, kind string, runID int64) (*types.Artifact, error) {
+	var artifact types.Artifact
+	query := `SELECT ` + artifactColumns + ` FROM artifacts
+	          WHERE repo = ? AND component = ? AND kind = ? AND run_id < ?
+	          ORDER BY CASE WHEN run_id IN (
+	                       SELECT b.run_id FROM baseline_samples bs JOIN benchmarks b ON b.id = bs.benchmark_id
+	                       WHERE bs.repo = ? AND bs.component = ? AND bs.metric = ?)
+	                   THEN 0 ELSE 1 END, run_id DESC
+	          LIMIT 1`
+	if err := s.get(&artifact, query, repo, component, kind, runID, repo, component, metric); err != nil {
+		return nil, err
+	}
+
+	return &artifact, nil
+}
+
+// SaveAnalysis replaces the stored verdicts for a run.
+func (s *SQLStore) SaveAnalysis(runID int64, results []AnalysisResult) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(tx.Rebind(`DELETE FROM analysis_results WHERE run_id = ?`), runID); err != nil {
+		return fmt.Errorf("failed to clear analysis results: %w", err)
+	}
+
+	query := tx.Rebind(`INSERT INTO analysis_results (run_id, repo, commit_hash, pr_number, component,
+	                    metric, unit, direction, is_regression, current_value, baseline_value, percent_change,
+	                    absolute_change, threshold_mode, threshold, confidence_score, sample_size, p_value,
+	                    baseline_source, baseline_commit, error, created_at)
+	                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	for _, r := range results {
+		if r.Metric == "" {
+			r.Metric = types.DefaultMetric
+		}
+		_, err := tx.Exec(query, runID, r.Repo, r.CommitHash, r.PRNumber, r.Component, r.Metric, r.Unit,
+			r.Direction, r.IsRegression,
+			r.CurrentValue, r.BaselineValue, r.PercentChange, r.AbsoluteChange, r.ThresholdMode,
+			r.Threshold, r.ConfidenceScore, r.SampleSize, r.PValue, r.BaselineSource, r.BaselineCommit,
+			r.Error, r.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save analysis result: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) RunAnalysis(runID int64) ([]AnalysisResult, error) {
+	var results []AnalysisResult
+	query := `SELECT ` + analysisColumns + ` FROM analysis_results WHERE run_id = ? ORDER BY id`
+	if err := s.sel(&results, query, runID); err != nil {
+		return nil, fmt.Errorf("failed to load analysis results: %w", err)
+	}
+
+	return results, nil
+}
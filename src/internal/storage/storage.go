@@ -0,0 +1,170 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package storage
+
+import (
+	"errors"
+
+	"regression-ci/pkg/types"
+)
+
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrBackupUnsupported = errors.New("online backups are only supported for sqlite")
+)
+
+// Store is everything the detector and server persist. Implementations must
+// be safe to share between replicas, so all read-modify-write sequences are
+// done inside a single call.
+type Store interface {
+	BenchmarkStore
+	RunStore
+	ArtifactStore
+	BaselineStore
+	ConfigStore
+	PRStore
+	JobStore
+	MaintenanceStore
+	TransferStore
+
+	Ping() error
+	Close() error
+}
+
+type BenchmarkStore interface {
+	InsertBenchmarks(benchmarks []types.Benchmark) error
+	RecentBenchmarks(repo, component, metric string, limit int) ([]types.Benchmark, error)
+	CommitBenchmarks(repo, component, metric, commit string) ([]types.Benchmark, error)
+	LastBenchmarkBefore(repo, branch, component, metric, commit string) (*types.Benchmark, error)
+	BenchmarkSeries(repo string) ([]Series, error)
+	Repos() ([]types.RepoSummary, error)
+	SeriesSummaries(repo string) ([]types.SeriesSummary, error)
+}
+
+type RunStore interface {
+	CreateRun(run *types.Run, benchmarks []types.Benchmark) error
+	Run(id int64) (*types.Run, error)
+	LatestCommitRun(repo, commit string) (*types.Run, error)
+	RunBenchmarks(id int64) ([]types.Benchmark, error)
+	SaveAnalysis(runID int64, results []AnalysisResult) error
+	RunAnalysis(runID int64) ([]AnalysisResult, error)
+	DeleteRun(id int64) error
+}
+
+type ArtifactStore interface {
+	SaveArtifacts(artifacts []types.Artifact) error
+	RunArtifacts(runID int64) ([]types.Artifact, error)
+	Artifact(runID int64, name string) (*types.Artifact, error)
+	BaselineProfile(repo, component, metric, kind string, runID int64) (*types.Artifact, error)
+}
+
+type BaselineStore interface {
+	Baseline(repo, component, metric string) (*types.Baseline, error)
+	BaselineSource(repo, component, metric string) (*BaselineSource, error)
+	BaselineSamples(repo, component, metric string) ([]types.Benchmark, error)
+	SaveBaseline(baseline types.Baseline, source BaselineSource, samples []types.Benchmark) error
+	DeleteBaseline(repo, component, metric string) error
+}
+
+type ConfigStore interface {
+	RepoConfig(repo string) (*types.RepoConfig, error)
+	SaveRepoConfig(config types.RepoConfig) error
+	SaveRepoFile(file RepoFile) error
+	RepoFile(repo, commit string) (*RepoFile, error)
+	LatestRepoFile(repo, branch, source string) (*RepoFile, error)
+	LatestDefaultBranchRepoFile(repo, source string) (*RepoFile, error)
+}
+
+type PRStore interface {
+	SavePRReport(report types.PRReport) error
+	PRReport(repo string, prNumber int) (*types.PRReport, error)
+	SetCheckRunID(repo string, prNumber int, checkRunID int64) error
+	AddAcknowledgement(ack types.Acknowledgement) error
+	Acknowledgements(repo string, prNumber int) ([]types.Acknowledgement, error)
+	SetPRLabels(repo string, prNumber int, labels []string) error
+	PRLabels(repo string, prNumber int) ([]string, error)
+}
+
+type JobStore interface {
+	CreateBisection(b *types.Bisection) error
+	UpdateBisection(b *types.Bisection) error
+	PendingBisections(repo, status, commit string) ([]types.Bisection, error)
+	HasBisection(repo, badCommit string) (bool, error)
+	Bisections(repo string) ([]types.Bisection, error)
+}
+
+type MaintenanceStore interface {
+	DownsampleBenchmarks(before int64, granularity string) (DownsampleStats, error)
+	Vacuum() error
+	Backup(path string) error
+}
+
+// TransferStore moves a repository's history between instances. Exports
+// stream rows to fn; imports take one batch per call and are idempotent.
+type TransferStore interface {
+	ExportRuns(repo string, fn func(types.Run) error) error
+	ExportBenchmarks(repo string, fn func(TransferBenchmark) error) error
+	ExportAggregates(repo string, fn func(BenchmarkAggregate) error) error
+	ExportBaselines(repo string, fn func(TransferBaseline) error) error
+	ExportRepoFiles(repo string, fn func(RepoFile) error) error
+	ImportRuns(runs []types.Run) (int, error)
+	ImportBenchmarks(samples []TransferBenchmark) (int, error)
+	ImportAggregates(aggregates []BenchmarkAggregate) (int, error)
+	ImportBaselines(baselines []TransferBaseline) (int, error)
+}
+
+// Series identifies one metric of one component, the unit history and
+// baselines are kept in.
+type Series struct {
+	Component string `db:"component"`
+	Metric    string `db:"metric"`
+}
+
+type BaselineSource struct {
+	Source       string `db:"source"`
+	Estimator    string `db:"estimator"`
+	PinnedCommit string `db:"pinned_commit"`
+}
+
+// AnalysisResult is one component's verdict within a run.
+type AnalysisResult struct {
+	ID              int64    `db:"id"`
+	RunID           int64    `db:"run_id"`
+	Repo            string   `db:"repo"`
+	CommitHash      string   `db:"commit_hash"`
+	PRNumber        int      `db:"pr_number"`
+	Component       string   `db:"component"`
+	Metric          string   `db:"metric"`
+	Unit            string   `db:"unit"`
+	Direction       string   `db:"direction"`
+	IsRegression    bool     `db:"is_regression"`
+	CurrentValue    float64  `db:"current_value"`
+	BaselineValue   float64  `db:"baseline_value"`
+	PercentChange   float64  `db:"percent_change"`
+	AbsoluteChange  float64  `db:"absolute_change"`
+	ThresholdMode   string   `db:"threshold_mode"`
+	Threshold       float64  `db:"threshold"`
+	ConfidenceScore float64  `db:"confidence_score"`
+	SampleSize      int      `db:"sample_size"`
+	PValue          *float64 `db:"p_value"`
+	BaselineSource  string   `db:"baseline_source"`
+	BaselineCommit  string   `db:"baseline_commit"`
+	Error           string   `db:"error"`
+	CreatedAt       int64    `db:"created_at"`
+}
+
+// RepoFile is a cached fetch of a repository's config file. Content is the
+// JSON-encoded config.RepoFile, empty when the commit has no file. Branch is
+// where the commit was seen and DefaultBranch whether that was the
+// repository's default branch at the time.
+type RepoFile struct {
+	Repo          string `db:"repo"`
+	CommitHash    string `db:"commit_hash"`
+	Branch        string `db:"branch"`
+	DefaultBranch bool   `db:"default_branch"`
+	Source        string `db:"source"`
+	Content       string `db:"content"`
+	Problems      string `db:"problems"`
+	FetchedAt     int64  `db:"fetched_at"`
+}
@@ -0,0 +1,330 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"regression-ci/pkg/types"
+)
+
+// TransferBenchmark is a raw sample keyed by its run's portable uid rather
+// than the local run id. RunUID is empty for samples recorded without a run.
+type TransferBenchmark struct {
+	types.Benchmark
+	RunUID string `db:"run_uid"`
+}
+
+// Key identifies the group a sample is deduplicated in on import: its run,
+// component and metric, or its commit and timestamp when it has no run.
+func (b TransferBenchmark) Key() string {
+	if b.RunUID != "" {
+		return strings.Join([]string{b.RunUID, b.Component, b.Metric}, "\x00")
+	}
+	return strings.Join([]string{"", b.Branch, b.CommitHash, b.Component, b.Metric,
+		strconv.FormatInt(b.Timestamp, 10)}, "\x00")
+}
+
+type TransferBaseline struct {
+	types.Baseline
+	BaselineSource
+}
+
+// BenchmarkAggregate is one downsampled bucket of history.
+type BenchmarkAggregate struct {
+	Repo           string  `db:"repo"`
+	Branch         string  `db:"branch"`
+	Component      string  `db:"component"`
+	Metric         string  `db:"metric"`
+	Bucket         string  `db:"bucket"`
+	CommitHash     string  `db:"commit_hash"`
+	MinValue       float64 `db:"min_value"`
+	MedianValue    float64 `db:"median_value"`
+	MaxValue       float64 `db:"max_value"`
+	SampleCount    int     `db:"sample_count"`
+	FirstTimestamp int64   `db:"first_timestamp"`
+	LastTimestamp  int64   `db:"last_timestamp"`
+}
+
+// stream scans a query row by row into fn so exports never hold a whole
+// table in memory.
+func stream[T any](db *sqlx.DB, query string, args []interface{}, fn func(T) error) error {
+	rows, err := db.Queryx(db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item T
+		if err := rows.StructScan(&item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ExportRuns leaves out bisection runs: they only mean something to the
+// bisection they answered, which is not part of a bundle.
+func (s *SQLStore) ExportRuns(repo string, fn func(types.Run) error) error {
+	query := `SELECT ` + runColumns + ` FROM runs WHERE repo = ? AND bisection_id = 0 ORDER BY id`
+	if err := stream(s.db, query, []interface{}{repo}, fn); err != nil {
+		return fmt.Errorf("failed to export runs: %w", err)
+	}
+
+	return nil
+}
+
+// ExportBenchmarks emits raw samples, apart from those of bisection runs,
+// ordered so that every import group (see TransferBenchmark.Key) is
+// contiguous.
+func (s *SQLStore) ExportBenchmarks(repo string, fn func(TransferBenchmark) error) error {
+	query := `SELECT b.id, b.repo, b.branch, b.commit_hash, b.component, b.metric, b.value, b.timestamp,
+	                 COALESCE(b.run_id, 0) AS run_id, COALESCE(r.uid, '') AS run_uid
+	          FROM benchmarks b LEFT JOIN runs r ON r.id = b.run_id
+	          WHERE b.repo = ? AND COALESCE(r.bisection_id, 0) = 0
+	          ORDER BY COALESCE(b.run_id, 0), b.component, b.metric, b.branch, b.commit_hash, b.timestamp, b.id`
+	if err := stream(s.db, query, []interface{}{repo}, fn); err != nil {
+		return fmt.Errorf("failed to export benchmarks: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) ExportAggregates(repo string, fn func(BenchmarkAggregate) error) error {
+	query := `SELECT repo, branch, component, metric, bucket, commit_hash, min_value, median_value,
+	                 max_value, sample_count, first_timestamp, last_timestamp
+	          FROM benchmark_aggregates WHERE repo = ? ORDER BY id`
+	if err := stream(s.db, query, []interface{}{repo}, fn); err != nil {
+		return fmt.Errorf("failed to export aggregates: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) ExportBaselines(repo string, fn func(TransferBaseline) error) error {
+	query := `SELECT b.repo, b.component, b.metric, b.baseline_value, b.sample_count, b.updated_at,
+	                 COALESCE(s.source, '') AS source, COALESCE(s.estimator, '') AS estimator,
+	                 COALESCE(s.pinned_commit, '') AS pinned_commit
+	          FROM baselines b
+	          LEFT JOIN baseline_sources s
+	              ON s.repo = b.repo AND s.component = b.component AND s.metric = b.metric
+	          WHERE b.repo = ? ORDER BY b.component, b.metric`
+	if err := stream(s.db, query, []interface{}{repo}, fn); err != nil {
+		return fmt.Errorf("failed to export baselines: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) ExportRepoFiles(repo string, fn func(RepoFile) error) error {
+	query := `SELECT ` + repoFileColumns + ` FROM repo_files WHERE repo = ? ORDER BY fetched_at, commit_hash`
+	if err := stream(s.db, query, []interface{}{repo}, fn); err != nil {
+		return fmt.Errorf("failed to export repo files: %w", err)
+	}
+
+	return nil
+}
+
+// ImportRuns inserts runs whose uid is not already present for their repo
+// and returns how many were new.
+func (s *SQLStore) ImportRuns(runs []types.Run) (int, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := tx.Rebind(`INSERT INTO runs (uid, repo, branch, commit_hash, pr_number, ci_url, runner,
+	                    started_at, finished_at, metadata)
+	                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	                    ON CONFLICT (repo, uid) DO NOTHING`)
+	imported := 0
+	for _, run := range runs {
+		if run.UID == "" {
+			return 0, fmt.Errorf("run for commit %s has no uid", run.CommitHash)
+		}
+		res, err := tx.Exec(query, run.UID, run.Repo, run.Branch, run.CommitHash, run.PRNumber, run.CIURL,
+			run.Runner, run.StartedAt, run.FinishedAt, run.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import run: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			imported++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return imported, nil
+}
+
+// ImportBenchmarks inserts samples for every group that has no samples
+// stored yet. Samples sharing a key must arrive in the same call, or the
+// later part of the group is taken for a duplicate.
+func (s *SQLStore) ImportBenchmarks(samples []TransferBenchmark) (int, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	runIDs := make(map[[2]string]int64)
+	decided := make(map[string]bool)
+	var insert []types.Benchmark
+	for _, sample := range samples {
+		if sample.Metric == "" {
+			sample.Metric = types.DefaultMetric
+		}
+
+		sample.RunID = 0
+		if sample.RunUID != "" {
+			run := [2]string{sample.Repo, sample.RunUID}
+			id, ok := runIDs[run]
+			if !ok {
+				err := tx.Get(&id, tx.Rebind(`SELECT id FROM runs WHERE repo = ? AND uid = ?`), run[0], run[1])
+				if err != nil {
+					return 0, fmt.Errorf("failed to resolve run %s: %w", sample.RunUID, err)
+				}
+				runIDs[run] = id
+			}
+			sample.RunID = id
+		}
+
+		key := sample.Key()
+		fresh, ok := decided[key]
+		if !ok {
+			var count int
+			if sample.RunID != 0 {
+				err = tx.Get(&count, tx.Rebind(`SELECT COUNT(*) FROM benchmarks
+				             WHERE run_id = ? AND component = ? AND metric = ?`),
+					sample.RunID, sample.Component, sample.Metric)
+			} else {
+				err = tx.Get(&count, tx.Rebind(`SELECT COUNT(*) FROM benchmarks
+				             WHERE repo = ? AND branch = ? AND commit_hash = ? AND component = ?
+				             AND metric = ? AND timestamp = ? AND run_id IS NULL`),
+					sample.Repo, sample.Branch, sample.CommitHash, sample.Component, sample.Metric, sample.Timestamp)
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to check existing samples: %w", err)
+			}
+			fresh = count == 0
+			decided[key] = fresh
+		}
+		if fresh {
+			insert = append(insert, sample.Benchmark)
+		}
+	}
+
+	if err := insertBenchmarks(tx, insert); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(insert), nil
+}
+
+// ImportAggregates inserts buckets that are not stored yet. Unlike
+// downsampling it never merges, so importing twice changes nothing.
+func (s *SQLStore) ImportAggregates(aggregates []BenchmarkAggregate) (int, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := tx.Rebind(`INSERT INTO benchmark_aggregates (repo, branch, component, metric, bucket, commit_hash,
+	                    min_value, median_value, max_value, sample_count, first_timestamp, last_timestamp)
+	                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	                    ON CONFLICT (repo, branch, component, metric, bucket) DO NOTHING`)
+	imported := 0
+	for _, a := range aggregates {
+		if a.Metric == "" {
+			a.Metric = types.DefaultMetric
+		}
+		res, err := tx.Exec(query, a.Repo, a.Branch, a.Component, a.Metric, a.Bucket, a.CommitHash,
+			a.MinValue, a.MedianValue, a.MaxValue, a.SampleCount, a.FirstTimestamp, a.LastTimestamp)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import aggregate: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			imported++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return imported, nil
+}
+
+// ImportBaselines upserts baselines and their sources. Sample provenance
+// points at local benchmark ids and is not carried over, so the samples and
+// source of the baseline being replaced are dropped rather than left to mix
+// with the imported value.
+func (s *SQLStore) ImportBaselines(baselines []TransferBaseline) (int, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	baselineQuery := tx.Rebind(`INSERT INTO baselines (repo, component, metric, baseline_value, sample_count,
+	                                updated_at)
+	                            VALUES (?, ?, ?, ?, ?, ?)
+	                            ON CONFLICT (repo, component, metric) DO UPDATE SET
+	                                baseline_value = excluded.baseline_value,
+	                                sample_count = excluded.sample_count,
+	                                updated_at = excluded.updated_at`)
+	sourceQuery := tx.Rebind(`INSERT INTO baseline_sources (repo, component, metric, source, estimator,
+	                              pinned_commit)
+	                          VALUES (?, ?, ?, ?, ?, ?)
+	                          ON CONFLICT (repo, component, metric) DO UPDATE SET
+	                              source = excluded.source,
+	                              estimator = excluded.estimator,
+	                              pinned_commit = excluded.pinned_commit`)
+	samplesQuery := tx.Rebind(`DELETE FROM baseline_samples WHERE repo = ? AND component = ? AND metric = ?`)
+	clearSourceQuery := tx.Rebind(`DELETE FROM baseline_sources WHERE repo = ? AND component = ? AND metric = ?`)
+	for _, b := range baselines {
+		if b.Metric == "" {
+			b.Metric = types.DefaultMetric
+		}
+		_, err := tx.Exec(baselineQuery, b.Repo, b.Component, b.Metric, b.BaselineValue, b.SampleCount, b.UpdatedAt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import baseline: %w", err)
+		}
+		if _, err := tx.Exec(samplesQuery, b.Repo, b.Component, b.Metric); err != nil {
+			return 0, fmt.Errorf("failed to clear baseline samples: %w", err)
+		}
+		if b.Source == "" {
+			if _, err := tx.Exec(clearSourceQuery, b.Repo, b.Component, b.Metric); err != nil {
+				return 0, fmt.Errorf("failed to clear baseline source: %w", err)
+			}
+			continue
+		}
+		_, err = tx.Exec(sourceQuery, b.Repo, b.Component, b.Metric, b.Source, b.Estimator, b.PinnedCommit)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import baseline source: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(baselines), nil
+}
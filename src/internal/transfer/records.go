@@ -0,0 +1,332 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"regression-ci/pkg/types"
+)
+
+// record is one row of a bundle table. JSON Lines use the struct tags; CSV
+// uses row and parse, in the order of the table's columns.
+type record interface {
+	row() []string
+	parse(fields *fieldReader)
+}
+
+type runRecord struct {
+	UID        string         `json:"uid"`
+	Branch     string         `json:"branch"`
+	CommitHash string         `json:"commit_hash"`
+	PRNumber   int            `json:"pr_number"`
+	CIURL      string         `json:"ci_url"`
+	Runner     string         `json:"runner"`
+	StartedAt  int64          `json:"started_at"`
+	FinishedAt int64          `json:"finished_at"`
+	Metadata   types.Metadata `json:"metadata"`
+}
+
+var runColumns = []string{"uid", "branch", "commit_hash", "pr_number", "ci_url", "runner",
+	"started_at", "finished_at", "metadata"}
+
+func (r *runRecord) row() []string {
+	metadata, _ := json.Marshal(r.Metadata)
+	return []string{r.UID, r.Branch, r.CommitHash, strconv.Itoa(r.PRNumber), r.CIURL, r.Runner,
+		formatInt(r.StartedAt), formatInt(r.FinishedAt), string(metadata)}
+}
+
+func (r *runRecord) parse(f *fieldReader) {
+	r.UID, r.Branch, r.CommitHash = f.str(), f.str(), f.str()
+	r.PRNumber = int(f.int())
+	r.CIURL, r.Runner = f.str(), f.str()
+	r.StartedAt, r.FinishedAt = f.int(), f.int()
+	f.json(&r.Metadata)
+}
+
+type benchmarkRecord struct {
+	RunUID     string  `json:"run_uid,omitempty"`
+	Branch     string  `json:"branch"`
+	CommitHash string  `json:"commit_hash"`
+	Component  string  `json:"component"`
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+var benchmarkColumns = []string{"run_uid", "branch", "commit_hash", "component", "metric", "value", "timestamp"}
+
+func (r *benchmarkRecord) row() []string {
+	return []string{r.RunUID, r.Branch, r.CommitHash, r.Component, r.Metric, formatFloat(r.Value),
+		formatInt(r.Timestamp)}
+}
+
+func (r *benchmarkRecord) parse(f *fieldReader) {
+	r.RunUID, r.Branch, r.CommitHash, r.Component, r.Metric = f.str(), f.str(), f.str(), f.str(), f.str()
+	r.Value = f.float()
+	r.Timestamp = f.int()
+}
+
+type aggregateRecord struct {
+	Branch         string  `json:"branch"`
+	Component      string  `json:"component"`
+	Metric         string  `json:"metric"`
+	Bucket         string  `json:"bucket"`
+	CommitHash     string  `json:"commit_hash"`
+	MinValue       float64 `json:"min_value"`
+	MedianValue    float64 `json:"median_value"`
+	MaxValue       float64 `json:"max_value"`
+	SampleCount    int     `json:"sample_count"`
+	FirstTimestamp int64   `json:"first_timestamp"`
+	LastTimestamp  int64   `json:"last_timestamp"`
+}
+
+var aggregateColumns = []string{"branch", "component", "metric", "bucket", "commit_hash", "min_value",
+	"median_value", "max_value", "sample_count", "first_timestamp", "last_timestamp"}
+
+func (r *aggregateRecord) row() []string {
+	return []string{r.Branch, r.Component, r.Metric, r.Bucket, r.CommitHash, formatFloat(r.MinValue),
+		formatFloat(r.MedianValue), formatFloat(r.MaxValue), strconv.Itoa(r.SampleCount),
+		formatInt(r.FirstTimestamp), formatInt(r.LastTimestamp)}
+}
+
+func (r *aggregateRecord) parse(f *fieldReader) {
+	r.Branch, r.Component, r.Metric, r.Bucket, r.CommitHash = f.str(), f.str(), f.str(), f.str(), f.str()
+	r.MinValue, r.MedianValue, r.MaxValue = f.float(), f.float(), f.float()
+	r.SampleCount = int(f.int())
+	r.FirstTimestamp, r.LastTimestamp = f.int(), f.int()
+}
+
+type baselineRecord struct {
+	Component     string  `json:"component"`
+	Metric        string  `json:"metric"`
+	BaselineValue float64 `json:"baseline_value"`
+	SampleCount   int     `json:"sample_count"`
+	UpdatedAt     int64   `json:"updated_at"`
+	Source        string  `json:"source,omitempty"`
+	Estimator     string  `json:"estimator,omitempty"`
+	PinnedCommit  string  `json:"pinned_commit,omitempty"`
+}
+
+var baselineColumns = []string{"component", "metric", "baseline_value", "sample_count", "updated_at",
+	"source", "estimator", "pinned_commit"}
+
+func (r *baselineRecord) row() []string {
+	return []string{r.Component, r.Metric, formatFloat(r.BaselineValue), strconv.Itoa(r.SampleCount),
+		formatInt(r.UpdatedAt), r.Source, r.Estimator, r.PinnedCommit}
+}
+
+func (r *baselineRecord) parse(f *fieldReader) {
+	r.Component, r.Metric = f.str(), f.str()
+	r.BaselineValue = f.float()
+	r.SampleCount = int(f.int())
+	r.UpdatedAt = f.int()
+	r.Source, r.Estimator, r.PinnedCommit = f.str(), f.str(), f.str()
+}
+
+type configRecord struct {
+	ThresholdPercent float64 `json:"threshold_percent"`
+	MinSamples       int     `json:"min_samples"`
+	Enabled          bool    `json:"enabled"`
+}
+
+var configColumns = []string{"threshold_percent", "min_samples", "enabled"}
+
+func (r *configRecord) row() []string {
+	return []string{formatFloat(r.ThresholdPercent), strconv.Itoa(r.MinSamples), strconv.FormatBool(r.Enabled)}
+}
+
+func (r *configRecord) parse(f *fieldReader) {
+	r.ThresholdPercent = f.float()
+	r.MinSamples = int(f.int())
+	r.Enabled = f.bool()
+}
+
+type repoFileRecord struct {
+	CommitHash    string `json:"commit_hash"`
+	Source        string `json:"source"`
+	Content       string `json:"content"`
+	Problems      string `json:"problems"`
+	FetchedAt     int64  `json:"fetched_at"`
+	Branch        string `json:"branch,omitempty"`
+	DefaultBranch bool   `json:"default_branch,omitempty"`
+}
+
+var repoFileColumns = []string{"commit_hash", "source", "content", "problems", "fetched_at", "branch",
+	"default_branch"}
+
+func (r *repoFileRecord) row() []string {
+	return []string{r.CommitHash, r.Source, r.Content, r.Problems, formatInt(r.FetchedAt), r.Branch,
+		strconv.FormatBool(r.DefaultBranch)}
+}
+
+// parse accepts bundles from before branches were recorded, which lack the
+// last two columns.
+func (r *repoFileRecord) parse(f *fieldReader) {
+	r.CommitHash, r.Source, r.Content, r.Problems = f.str(), f.str(), f.str(), f.str()
+	r.FetchedAt = f.int()
+	r.Branch = f.str()
+	r.DefaultBranch = f.str() == "true"
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// fieldReader hands out CSV fields in column order and keeps the first
+// conversion error, so parse methods stay a flat list of assignments.
+type fieldReader struct {
+	fields  []string
+	columns []string
+	next    int
+	err     error
+}
+
+func (f *fieldReader) str() string {
+	if f.next >= len(f.fields) {
+		return ""
+	}
+	f.next++
+	return f.fields[f.next-1]
+}
+
+func (f *fieldReader) fail(err error) {
+	if f.err == nil {
+		f.err = fmt.Errorf("column %s: %w", f.columns[f.next-1], err)
+	}
+}
+
+func (f *fieldReader) int() int64 {
+	v, err := strconv.ParseInt(f.str(), 10, 64)
+	if err != nil {
+		f.fail(err)
+	}
+	return v
+}
+
+func (f *fieldReader) float() float64 {
+	v, err := strconv.ParseFloat(f.str(), 64)
+	if err != nil {
+		f.fail(err)
+	}
+	return v
+}
+
+func (f *fieldReader) bool() bool {
+	v, err := strconv.ParseBool(f.str())
+	if err != nil {
+		f.fail(err)
+	}
+	return v
+}
+
+func (f *fieldReader) json(dest interface{}) {
+	value := f.str()
+	if value == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(value), dest); err != nil {
+		f.fail(err)
+	}
+}
+
+type encoder struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+func newEncoder(w io.Writer, format string, columns []string) (*encoder, error) {
+	if format == FormatCSV {
+		enc := &encoder{csv: csv.NewWriter(w)}
+		return enc, enc.csv.Write(columns)
+	}
+	return &encoder{json: json.NewEncoder(w)}, nil
+}
+
+func (e *encoder) encode(rec record) error {
+	if e.csv != nil {
+		return e.csv.Write(rec.row())
+	}
+	return e.json.Encode(rec)
+}
+
+func (e *encoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+type decoder struct {
+	csv     *csv.Reader
+	json    *json.Decoder
+	columns []string
+	index   []int
+}
+
+// newDecoder reads the CSV header up front and maps it onto the table's
+// columns, so bundles with reordered columns still load. Columns missing
+// from older bundles read as empty and take their defaults.
+func newDecoder(r io.Reader, format string, columns []string) (*decoder, error) {
+	if format != FormatCSV {
+		return &decoder{json: json.NewDecoder(r)}, nil
+	}
+
+	dec := &decoder{csv: csv.NewReader(r), columns: columns}
+	dec.csv.ReuseRecord = true
+	header, err := dec.csv.Read()
+	if err == io.EOF {
+		return dec, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+	for _, column := range columns {
+		i, ok := positions[column]
+		if !ok {
+			i = -1
+		}
+		dec.index = append(dec.index, i)
+	}
+
+	return dec, nil
+}
+
+func (d *decoder) next(rec record) error {
+	if d.json != nil {
+		return d.json.Decode(rec)
+	}
+	if d.index == nil {
+		return io.EOF
+	}
+
+	fields, err := d.csv.Read()
+	if err != nil {
+		return err
+	}
+	ordered := make([]string, len(d.index))
+	for i, pos := range d.index {
+		if pos >= 0 {
+			ordered[i] = fields[pos]
+		}
+	}
+
+	reader := &fieldReader{fields: ordered, columns: d.columns}
+	rec.parse(reader)
+	return reader.err
+}
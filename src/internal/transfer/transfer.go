@@ -0,0 +1,436 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package transfer
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"regression-ci/internal/database"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+
+	// BundleVersion is bumped whenever a table or column changes meaning.
+	BundleVersion = 1
+
+	manifestName = "manifest.json"
+	batchSize    = 500
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+	ErrInvalidBundle = errors.New("invalid bundle")
+)
+
+type Store interface {
+	storage.TransferStore
+	storage.ConfigStore
+}
+
+// Manifest is the first entry of every bundle. Tables lists the entries
+// that follow, in the order they must be imported.
+type Manifest struct {
+	Version       int      `json:"version"`
+	Repo          string   `json:"repo"`
+	Format        string   `json:"format"`
+	SchemaVersion int      `json:"schema_version"`
+	ExportedAt    int64    `json:"exported_at"`
+	Tables        []string `json:"tables"`
+}
+
+type TableStats struct {
+	Rows     int `json:"rows"`
+	Imported int `json:"imported"`
+}
+
+type ImportResult struct {
+	Repo     string                `json:"repo"`
+	Manifest Manifest              `json:"manifest"`
+	Tables   map[string]TableStats `json:"tables"`
+}
+
+type table struct {
+	name    string
+	columns []string
+	export  func(store Store, repo string, emit func(record) error) error
+	load    func(store Store, repo string, dec *decoder) (TableStats, error)
+}
+
+// Runs come before benchmarks so sample run uids resolve on import.
+var tables = []table{
+	{name: "runs", columns: runColumns, export: exportRuns, load: loadRuns},
+	{name: "benchmarks", columns: benchmarkColumns, export: exportBenchmarks, load: loadBenchmarks},
+	{name: "aggregates", columns: aggregateColumns, export: exportAggregates, load: loadAggregates},
+	{name: "baselines", columns: baselineColumns, export: exportBaselines, load: loadBaselines},
+	{name: "config", columns: configColumns, export: exportConfig, load: loadConfig},
+	{name: "repo_files", columns: repoFileColumns, export: exportRepoFiles, load: loadRepoFiles},
+}
+
+func ValidFormat(format string) bool {
+	return format == FormatJSONL || format == FormatCSV
+}
+
+// Export writes repo's history to w as a tar.gz bundle with one file per
+// table. Each table is spooled to a temporary file first, because tar needs
+// an entry's size before its content; nothing is held in memory.
+func Export(store Store, repo, format string, w io.Writer) error {
+	if !ValidFormat(format) {
+		return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+
+	schemaVersion, err := database.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	manifest := Manifest{
+		Version:       BundleVersion,
+		Repo:          repo,
+		Format:        format,
+		SchemaVersion: schemaVersion,
+		ExportedAt:    time.Now().Unix(),
+	}
+	for _, t := range tables {
+		manifest.Tables = append(manifest.Tables, t.name+"."+format)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: manifestName, Mode: 0o644, Size: int64(len(data)), ModTime: time.Unix(manifest.ExportedAt, 0)}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	for _, t := range tables {
+		if err := writeTable(tw, store, repo, format, t, header.ModTime); err != nil {
+			return fmt.Errorf("failed to export %s: %w", t.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeTable(tw *tar.Writer, store Store, repo, format string, t table, modTime time.Time) error {
+	spool, err := os.CreateTemp("", "regression-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	buf := bufio.NewWriter(spool)
+	enc, err := newEncoder(buf, format, t.columns)
+	if err != nil {
+		return err
+	}
+	if err := t.export(store, repo, enc.encode); err != nil {
+		return err
+	}
+	if err := enc.flush(); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := &tar.Header{Name: t.name + "." + format, Mode: 0o644, Size: size, ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, spool)
+	return err
+}
+
+// Import loads a bundle into the store, as repo when it is set and under
+// the exported repository's name otherwise. Entries are read as they arrive
+// and written in batches, and importing the same bundle again adds nothing.
+func Import(store Store, r io.Reader, repo string) (*ImportResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if header.Name != manifestName {
+		return nil, fmt.Errorf("%w: expected %s first, found %s", ErrInvalidBundle, manifestName, header.Name)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest: %v", ErrInvalidBundle, err)
+	}
+	if manifest.Version < 1 || manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("%w: unsupported bundle version %d", ErrInvalidBundle, manifest.Version)
+	}
+	if !ValidFormat(manifest.Format) {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidBundle, manifest.Format)
+	}
+
+	result := &ImportResult{Repo: repo, Manifest: manifest, Tables: make(map[string]TableStats)}
+	if result.Repo == "" {
+		result.Repo = manifest.Repo
+	}
+	if result.Repo == "" {
+		return nil, fmt.Errorf("%w: manifest names no repository", ErrInvalidBundle)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+		name := strings.TrimSuffix(path.Base(header.Name), "."+manifest.Format)
+		t, ok := findTable(name)
+		if !ok {
+			return result, fmt.Errorf("%w: unexpected entry %s", ErrInvalidBundle, header.Name)
+		}
+
+		dec, err := newDecoder(bufio.NewReader(tr), manifest.Format, t.columns)
+		if err != nil {
+			return result, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, header.Name, err)
+		}
+		stats, err := t.load(store, result.Repo, dec)
+		result.Tables[t.name] = stats
+		if err != nil {
+			return result, fmt.Errorf("failed to import %s: %w", header.Name, err)
+		}
+	}
+
+	return result, nil
+}
+
+func findTable(name string) (table, bool) {
+	for _, t := range tables {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return table{}, false
+}
+
+// recordPtr lets loadTable allocate a record of type R and decode into it.
+type recordPtr[R any] interface {
+	*R
+	record
+}
+
+// loadTable decodes rows in batches of batchSize and hands each batch to
+// save. With a key function a batch is only cut between keys, so a group of
+// rows sharing a key is always saved together.
+func loadTable[R any, P recordPtr[R], T any](dec *decoder, convert func(*R) T, key func(T) string,
+	save func([]T) (int, error)) (TableStats, error) {
+	var stats TableStats
+	batch := make([]T, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := save(batch)
+		if err != nil {
+			return err
+		}
+		stats.Imported += n
+		batch = batch[:0]
+		return nil
+	}
+
+	lastKey := ""
+	for {
+		var rec R
+		err := dec.next(P(&rec))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("%w: row %d: %v", ErrInvalidBundle, stats.Rows+1, err)
+		}
+		stats.Rows++
+
+		item := convert(&rec)
+		if key == nil {
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return stats, err
+				}
+			}
+		} else if k := key(item); k != lastKey {
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return stats, err
+				}
+			}
+			lastKey = k
+		}
+		batch = append(batch, item)
+	}
+
+	return stats, flush()
+}
+
+func exportRuns(store Store, repo string, emit func(record) error) error {
+	return store.ExportRuns(repo, func(run types.Run) error {
+		return emit(&runRecord{UID: run.UID, Branch: run.Branch, CommitHash: run.CommitHash,
+			PRNumber: run.PRNumber, CIURL: run.CIURL, Runner: run.Runner, StartedAt: run.StartedAt,
+			FinishedAt: run.FinishedAt, Metadata: run.Metadata})
+	})
+}
+
+func loadRuns(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *runRecord) types.Run {
+		return types.Run{UID: r.UID, Repo: repo, Branch: r.Branch, CommitHash: r.CommitHash,
+			PRNumber: r.PRNumber, CIURL: r.CIURL, Runner: r.Runner, StartedAt: r.StartedAt,
+			FinishedAt: r.FinishedAt, Metadata: r.Metadata}
+	}
+	return loadTable(dec, convert, nil, store.ImportRuns)
+}
+
+func exportBenchmarks(store Store, repo string, emit func(record) error) error {
+	return store.ExportBenchmarks(repo, func(b storage.TransferBenchmark) error {
+		return emit(&benchmarkRecord{RunUID: b.RunUID, Branch: b.Branch, CommitHash: b.CommitHash,
+			Component: b.Component, Metric: b.Metric, Value: b.Value, Timestamp: b.Timestamp})
+	})
+}
+
+func loadBenchmarks(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *benchmarkRecord) storage.TransferBenchmark {
+		return storage.TransferBenchmark{
+			Benchmark: types.Benchmark{Repo: repo, Branch: r.Branch, CommitHash: r.CommitHash,
+				Component: r.Component, Metric: r.Metric, Value: r.Value, Timestamp: r.Timestamp},
+			RunUID: r.RunUID,
+		}
+	}
+	return loadTable(dec, convert, storage.TransferBenchmark.Key, store.ImportBenchmarks)
+}
+
+func exportAggregates(store Store, repo string, emit func(record) error) error {
+	return store.ExportAggregates(repo, func(a storage.BenchmarkAggregate) error {
+		return emit(&aggregateRecord{Branch: a.Branch, Component: a.Component, Metric: a.Metric,
+			Bucket: a.Bucket, CommitHash: a.CommitHash, MinValue: a.MinValue, MedianValue: a.MedianValue,
+			MaxValue: a.MaxValue, SampleCount: a.SampleCount, FirstTimestamp: a.FirstTimestamp,
+			LastTimestamp: a.LastTimestamp})
+	})
+}
+
+func loadAggregates(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *aggregateRecord) storage.BenchmarkAggregate {
+		return storage.BenchmarkAggregate{Repo: repo, Branch: r.Branch, Component: r.Component,
+			Metric: r.Metric, Bucket: r.Bucket, CommitHash: r.CommitHash, MinValue: r.MinValue,
+			MedianValue: r.MedianValue, MaxValue: r.MaxValue, SampleCount: r.SampleCount,
+			FirstTimestamp: r.FirstTimestamp, LastTimestamp: r.LastTimestamp}
+	}
+	return loadTable(dec, convert, nil, store.ImportAggregates)
+}
+
+func exportBaselines(store Store, repo string, emit func(record) error) error {
+	return store.ExportBaselines(repo, func(b storage.TransferBaseline) error {
+		return emit(&baselineRecord{Component: b.Component, Metric: b.Metric, BaselineValue: b.BaselineValue,
+			SampleCount: b.SampleCount, UpdatedAt: b.UpdatedAt, Source: b.Source, Estimator: b.Estimator,
+			PinnedCommit: b.PinnedCommit})
+	})
+}
+
+func loadBaselines(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *baselineRecord) storage.TransferBaseline {
+		return storage.TransferBaseline{
+			Baseline: types.Baseline{Repo: repo, Component: r.Component, Metric: r.Metric, BaselineValue: r.BaselineValue,
+				SampleCount: r.SampleCount, UpdatedAt: r.UpdatedAt},
+			BaselineSource: storage.BaselineSource{Source: r.Source, Estimator: r.Estimator,
+				PinnedCommit: r.PinnedCommit},
+		}
+	}
+	return loadTable(dec, convert, nil, store.ImportBaselines)
+}
+
+func exportConfig(store Store, repo string, emit func(record) error) error {
+	config, err := store.RepoConfig(repo)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return emit(&configRecord{ThresholdPercent: config.ThresholdPercent, MinSamples: config.MinSamples,
+		Enabled: config.Enabled})
+}
+
+func loadConfig(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *configRecord) types.RepoConfig {
+		return types.RepoConfig{Repo: repo, ThresholdPercent: r.ThresholdPercent, MinSamples: r.MinSamples,
+			Enabled: r.Enabled}
+	}
+	save := func(configs []types.RepoConfig) (int, error) {
+		for _, config := range configs {
+			if err := store.SaveRepoConfig(config); err != nil {
+				return 0, err
+			}
+		}
+		return len(configs), nil
+	}
+	return loadTable(dec, convert, nil, save)
+}
+
+func exportRepoFiles(store Store, repo string, emit func(record) error) error {
+	return store.ExportRepoFiles(repo, func(f storage.RepoFile) error {
+		return emit(&repoFileRecord{CommitHash: f.CommitHash, Source: f.Source, Content: f.Content,
+			Problems: f.Problems, FetchedAt: f.FetchedAt, Branch: f.Branch, DefaultBranch: f.DefaultBranch})
+	})
+}
+
+func loadRepoFiles(store Store, repo string, dec *decoder) (TableStats, error) {
+	convert := func(r *repoFileRecord) storage.RepoFile {
+		return storage.RepoFile{Repo: repo, CommitHash: r.CommitHash, Branch: r.Branch,
+			DefaultBranch: r.DefaultBranch, Source: r.Source, Content: r.Content, Problems: r.Problems,
+			FetchedAt: r.FetchedAt}
+	}
+	save := func(files []storage.RepoFile) (int, error) {
+		for _, file := range files {
+			if err := store.SaveRepoFile(file); err != nil {
+				return 0, err
+			}
+		}
+		return len(files), nil
+	}
+	return loadTable(dec, convert, nil, save)
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// CommitList is stored as a JSON array in a single TEXT column.
+type CommitList []string
+
+func (c CommitList) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(c))
+	return string(data), err
+}
+
+func (c *CommitList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	case nil:
+		*c = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into CommitList", src)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package types
+
+import "sort"
+
+// Directions say which way a metric improves. Times and allocations are
+// lower-is-better; throughputs are higher-is-better.
+const (
+	DirectionLower  = "lower"
+	DirectionHigher = "higher"
+)
+
+// Measurement is every sample of one metric of a component from a single
+// run, e.g. the -count=N ns/op values of a Go benchmark.
+type Measurement struct {
+	Component string    `json:"component"`
+	Metric    string    `json:"metric"`
+	Unit      string    `json:"unit,omitempty"`
+	Direction string    `json:"direction,omitempty"`
+	Samples   []float64 `json:"samples"`
+}
+
+// AllMeasurements returns the request's Components as single-sample
+// measurements of DefaultMetric, followed by its Measurements, with the
+// metric and direction defaults filled in.
+func (r AnalyzeRequest) AllMeasurements() []Measurement {
+	components := make([]string, 0, len(r.Components))
+	for component := range r.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	all := make([]Measurement, 0, len(components)+len(r.Measurements))
+	for _, component := range components {
+		all = append(all, Measurement{
+			Component: component,
+			Metric:    DefaultMetric,
+			Unit:      r.Units[component],
+			Direction: DirectionLower,
+			Samples:   []float64{r.Components[component]},
+		})
+	}
+
+	for _, m := range r.Measurements {
+		if m.Metric == "" {
+			m.Metric = DefaultMetric
+		}
+		if m.Direction == "" {
+			m.Direction = DirectionLower
+		}
+		all = append(all, m)
+	}
+
+	return all
+}
+
+// Value returns the mean of the samples reported for a component's metric.
+func (r AnalyzeRequest) Value(component, metric string) (float64, bool) {
+	for _, m := range r.AllMeasurements() {
+		if m.Component != component || m.Metric != metric || len(m.Samples) == 0 {
+			continue
+		}
+
+		sum := 0.0
+		for _, sample := range m.Samples {
+			sum += sample
+		}
+		return sum / float64(len(m.Samples)), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Metadata is free-form run metadata, stored as a JSON object in a TEXT column.
+type Metadata map[string]interface{}
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]interface{}(m))
+	return string(data), err
+}
+
+func (m *Metadata) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case []byte:
+		return json.Unmarshal(v, m)
+	case nil:
+		*m = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Metadata", src)
+}
@@ -4,62 +4,239 @@
 package types
 
 type AnalyzeRequest struct {
-	Repo       string                 `json:"repo" binding:"required"`
-	Branch     string                 `json:"branch" binding:"required"`
-	Commit     string                 `json:"commit" binding:"required"`
-	Components map[string]float64     `json:"components" binding:"required"`
-	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Repo         string             `json:"repo" binding:"required"`
+	Branch       string             `json:"branch" binding:"required"`
+	Commit       string             `json:"commit" binding:"required"`
+	PRNumber     int                `json:"pr_number,omitempty"`
+	Components   map[string]float64 `json:"components"`
+	Units        map[string]string  `json:"units,omitempty"`
+	Measurements []Measurement      `json:"measurements,omitempty"`
+	Metadata     Metadata           `json:"metadata,omitempty"`
+	CIURL        string             `json:"ci_url,omitempty"`
+	Runner       string             `json:"runner,omitempty"`
+	BisectionID  int64              `json:"bisection_id,omitempty"`
+	StartedAt    int64              `json:"started_at,omitempty"`
+	FinishedAt   int64              `json:"finished_at,omitempty"`
 }
 
 type RegressionResult struct {
-	IsRegression    bool    `json:"is_regression"`
-	CurrentValue    float64 `json:"current_value"`
-	BaselineValue   float64 `json:"baseline_value"`
-	PercentChange   float64 `json:"percent_change"`
-	ConfidenceScore float64 `json:"confidence_score"`
-	SampleSize      int     `json:"sample_size"`
+	IsRegression    bool     `json:"is_regression"`
+	CurrentValue    float64  `json:"current_value"`
+	BaselineValue   float64  `json:"baseline_value"`
+	PercentChange   float64  `json:"percent_change"`
+	AbsoluteChange  float64  `json:"absolute_change"`
+	ThresholdMode   string   `json:"threshold_mode"`
+	Threshold       float64  `json:"threshold"`
+	ConfidenceScore float64  `json:"confidence_score"`
+	SampleSize      int      `json:"sample_size"`
+	PValue          *float64 `json:"p_value,omitempty"`
+	BaselineSource  string   `json:"baseline_source,omitempty"`
+	BaselineCommit  string   `json:"baseline_commit,omitempty"`
 }
 
 type ComponentResult struct {
-	Component string            `json:"component"`
-	Result    *RegressionResult `json:"result"`
-	Error     string            `json:"error,omitempty"`
+	Component       string            `json:"component"`
+	Metric          string            `json:"metric,omitempty"`
+	Unit            string            `json:"unit,omitempty"`
+	Direction       string            `json:"direction,omitempty"`
+	Result          *RegressionResult `json:"result"`
+	Acknowledgement *Acknowledgement  `json:"acknowledgement,omitempty"`
+	ProfileDiffs    []ProfileDiff     `json:"profile_diffs,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// ProfileDiff compares a regressed component's profile with the one from
+// the most recent run behind its baseline. Function values are each
+// function's flat share of its profile in percent.
+type ProfileDiff struct {
+	Kind          string          `json:"kind"`
+	BaselineRunID int64           `json:"baseline_run_id"`
+	SampleType    string          `json:"sample_type"`
+	Unit          string          `json:"unit"`
+	BaselineTotal float64         `json:"baseline_total"`
+	CurrentTotal  float64         `json:"current_total"`
+	Functions     []FunctionDelta `json:"functions"`
+}
+
+type FunctionDelta struct {
+	Function string  `json:"function"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`
 }
 
 type AnalyzeResponse struct {
 	Repo       string            `json:"repo"`
 	Commit     string            `json:"commit"`
+	PRNumber   int               `json:"pr_number,omitempty"`
+	RunID      int64             `json:"run_id,omitempty"`
+	Policy     *VerdictPolicy    `json:"policy,omitempty"`
 	Components []ComponentResult `json:"components"`
 	Timestamp  int64             `json:"timestamp"`
 }
 
+type VerdictPolicy struct {
+	Ignore   bool `json:"ignore,omitempty"`
+	Strict   bool `json:"strict,omitempty"`
+	Accepted bool `json:"accepted,omitempty"`
+}
+
+type Acknowledgement struct {
+	ID        int64  `json:"id" db:"id"`
+	Repo      string `json:"repo" db:"repo"`
+	PRNumber  int    `json:"pr_number" db:"pr_number"`
+	HeadSHA   string `json:"head_sha" db:"head_sha"`
+	Component string `json:"component" db:"component"`
+	Action    string `json:"action" db:"action"`
+	Author    string `json:"author" db:"author"`
+	Reason    string `json:"reason,omitempty" db:"reason"`
+	CreatedAt int64  `json:"created_at" db:"created_at"`
+}
+
+type PRReport struct {
+	Repo       string `db:"repo"`
+	PRNumber   int    `db:"pr_number"`
+	HeadSHA    string `db:"head_sha"`
+	CheckRunID int64  `db:"check_run_id"`
+	Response   string `db:"response"`
+	UpdatedAt  int64  `db:"updated_at"`
+}
+
+type Bisection struct {
+	ID            int64      `json:"id" db:"id"`
+	Repo          string     `json:"repo" db:"repo"`
+	Branch        string     `json:"branch" db:"branch"`
+	Component     string     `json:"component" db:"component"`
+	Metric        string     `json:"metric" db:"metric"`
+	GoodCommit    string     `json:"good_commit" db:"good_commit"`
+	BadCommit     string     `json:"bad_commit" db:"bad_commit"`
+	GoodValue     float64    `json:"good_value" db:"good_value"`
+	BadValue      float64    `json:"bad_value" db:"bad_value"`
+	Candidates    CommitList `json:"candidates" db:"candidates"`
+	Low           int        `json:"low" db:"low"`
+	High          int        `json:"high" db:"high"`
+	PendingCommit string     `json:"pending_commit,omitempty" db:"pending_commit"`
+	Status        string     `json:"status" db:"status"`
+	Culprit       string     `json:"culprit,omitempty" db:"culprit"`
+	CreatedAt     int64      `json:"created_at" db:"created_at"`
+	UpdatedAt     int64      `json:"updated_at" db:"updated_at"`
+}
+
 type Baseline struct {
 	Repo          string  `json:"repo" db:"repo"`
 	Component     string  `json:"component" db:"component"`
+	Metric        string  `json:"metric" db:"metric"`
 	BaselineValue float64 `json:"baseline_value" db:"baseline_value"`
 	SampleCount   int     `json:"sample_count" db:"sample_count"`
 	UpdatedAt     int64   `json:"updated_at" db:"updated_at"`
 }
 
+type BaselineProvenance struct {
+	Baseline     Baseline    `json:"baseline"`
+	Source       string      `json:"source"`
+	Estimator    string      `json:"estimator"`
+	PinnedCommit string      `json:"pinned_commit,omitempty"`
+	Commits      []string    `json:"commits"`
+	Samples      []Benchmark `json:"samples"`
+}
+
+type PinBaselineRequest struct {
+	Commit string `json:"commit" binding:"required"`
+}
+
 type Benchmark struct {
 	ID         int64   `json:"id" db:"id"`
 	Repo       string  `json:"repo" db:"repo"`
 	Branch     string  `json:"branch" db:"branch"`
 	CommitHash string  `json:"commit_hash" db:"commit_hash"`
 	Component  string  `json:"component" db:"component"`
+	Metric     string  `json:"metric" db:"metric"`
 	Value      float64 `json:"value" db:"value"`
 	Timestamp  int64   `json:"timestamp" db:"timestamp"`
+	RunID      int64   `json:"run_id,omitempty" db:"run_id"`
+}
+
+// DefaultMetric names the single value reported per component through
+// AnalyzeRequest.Components.
+const DefaultMetric = "value"
+
+// Run groups the samples uploaded by a single CI job together with the
+// environment they were measured in.
+type Run struct {
+	ID          int64    `json:"id" db:"id"`
+	UID         string   `json:"uid" db:"uid"`
+	Repo        string   `json:"repo" db:"repo"`
+	Branch      string   `json:"branch" db:"branch"`
+	CommitHash  string   `json:"commit_hash" db:"commit_hash"`
+	PRNumber    int      `json:"pr_number,omitempty" db:"pr_number"`
+	CIURL       string   `json:"ci_url,omitempty" db:"ci_url"`
+	Runner      string   `json:"runner,omitempty" db:"runner"`
+	StartedAt   int64    `json:"started_at" db:"started_at"`
+	FinishedAt  int64    `json:"finished_at" db:"finished_at"`
+	Metadata    Metadata `json:"metadata,omitempty" db:"metadata"`
+	BisectionID int64    `json:"bisection_id,omitempty" db:"bisection_id"`
+}
+
+type RunDetail struct {
+	Run        Run              `json:"run"`
+	Benchmarks []Benchmark      `json:"benchmarks"`
+	Analysis   *AnalyzeResponse `json:"analysis,omitempty"`
+	Artifacts  []Artifact       `json:"artifacts,omitempty"`
+}
+
+// Artifact is a file uploaded alongside a run's results, such as a raw log
+// or a profile. The content lives in the artifact directory, not the database.
+// Profiles also record the component they were taken for and their kind.
+type Artifact struct {
+	ID          int64  `json:"id" db:"id"`
+	RunID       int64  `json:"run_id" db:"run_id"`
+	Repo        string `json:"repo" db:"repo"`
+	Name        string `json:"name" db:"name"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Component   string `json:"component,omitempty" db:"component"`
+	Kind        string `json:"kind,omitempty" db:"kind"`
+	Size        int64  `json:"size" db:"size"`
+	SHA256      string `json:"sha256" db:"sha256"`
+	CreatedAt   int64  `json:"created_at" db:"created_at"`
+}
+
+// DeletedRun is what deleting a run removed, and the baselines rebuilt
+// without its samples.
+type DeletedRun struct {
+	Run       Run        `json:"run"`
+	Samples   int        `json:"samples"`
+	Artifacts []Artifact `json:"artifacts"`
+	Baselines []Baseline `json:"rebuilt_baselines"`
+}
+
+// RepoSummary is a repository as the admin API lists it.
+type RepoSummary struct {
+	Repo      string `json:"repo" db:"repo"`
+	Runs      int    `json:"runs" db:"runs"`
+	Series    int    `json:"series" db:"series"`
+	LastRunAt int64  `json:"last_run_at" db:"last_run_at"`
+}
+
+// SeriesSummary is one metric of a component: how much history it has and
+// its baseline, if any.
+type SeriesSummary struct {
+	Component      string   `json:"component" db:"component"`
+	Metric         string   `json:"metric" db:"metric"`
+	Samples        int      `json:"samples" db:"samples"`
+	LastTimestamp  int64    `json:"last_timestamp" db:"last_timestamp"`
+	BaselineValue  *float64 `json:"baseline_value,omitempty" db:"baseline_value"`
+	BaselineSource string   `json:"baseline_source,omitempty" db:"baseline_source"`
 }
 
 type RepoConfig struct {
-	Repo             string                        `json:"repo" db:"repo"`
-	ThresholdPercent float64                       `json:"threshold_percent" db:"threshold_percent"`
-	MinSamples       int                           `json:"min_samples" db:"min_samples"`
-	Enabled          bool                          `json:"enabled" db:"enabled"`
-	Components       map[string]ComponentConfig   `json:"components,omitempty"`
+	Repo             string                     `json:"repo" db:"repo"`
+	ThresholdPercent float64                    `json:"threshold_percent" db:"threshold_percent"`
+	MinSamples       int                        `json:"min_samples" db:"min_samples"`
+	Enabled          bool                       `json:"enabled" db:"enabled"`
+	Components       map[string]ComponentConfig `json:"components,omitempty"`
 }
 
 type ComponentConfig struct {
 	CustomThreshold *float64 `json:"custom_threshold,omitempty"`
 	Enabled         bool     `json:"enabled"`
-}
\ No newline at end of file
+}
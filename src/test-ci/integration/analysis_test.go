@@ -0,0 +1,60 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"regression-ci/internal/regression"
+	"regression-ci/internal/report"
+	"regression-ci/pkg/types"
+)
+
+// commitAnalysis reads what `regression-ci analyze --wait` reads.
+func (s *testServer) commitAnalysis(commit string) *types.AnalyzeResponse {
+	s.t.Helper()
+	rec := s.admin(http.MethodGet, "/repos/octo%2Fapp/commits/"+commit+"/analysis", nil, false)
+	var resp types.AnalyzeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); rec.Code != http.StatusOK || err != nil {
+		s.t.Fatalf("commit analysis failed: %d %s", rec.Code, rec.Body)
+	}
+	return &resp
+}
+
+func TestCommitAnalysisAppliesPRAcknowledgements(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+
+	if open, _ := report.Regressions(s.commitAnalysis("head1")); open != 1 {
+		t.Fatalf("expected one open regression before accepting, got %d", open)
+	}
+
+	if rec := s.comment("maintainer", "/perf accept parse expected"); rec.Code != http.StatusOK {
+		t.Fatalf("accept failed: %d %s", rec.Code, rec.Body)
+	}
+	if open, acknowledged := report.Regressions(s.commitAnalysis("head1")); open != 0 || acknowledged != 1 {
+		t.Fatalf("expected the accepted regression to be closed, got %d open and %d acknowledged", open, acknowledged)
+	}
+}
+
+func TestCommitAnalysisAppliesPRLabels(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+
+	if rec := s.label("labeled", regression.LabelAccepted, regression.LabelAccepted); rec.Code != http.StatusOK {
+		t.Fatalf("label failed: %d %s", rec.Code, rec.Body)
+	}
+
+	resp := s.commitAnalysis("head1")
+	if resp.Policy == nil || !resp.Policy.Accepted {
+		t.Fatalf("expected the perf-accepted policy, got %+v", resp.Policy)
+	}
+	if open, _ := report.Regressions(resp); open != 0 {
+		t.Fatalf("expected no open regressions under perf-accepted, got %d", open)
+	}
+}
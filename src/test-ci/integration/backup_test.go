@@ -0,0 +1,151 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"regression-ci/internal/backup"
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func TestBackupRotationAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "regression.db")
+	backups := filepath.Join(dir, "backups")
+
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: target})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+
+	err = store.InsertBenchmarks([]types.Benchmark{
+		{Repo: "backup/repo", Branch: "main", CommitHash: "c1", Component: "parse", Value: 10, Timestamp: 1},
+	})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	// Back-to-back backups land in the same second; none may clobber another.
+	var created []string
+	for i := 0; i < 3; i++ {
+		info, err := backup.Create(store, backups, 2)
+		if err != nil {
+			t.Fatalf("backup %d failed: %v", i, err)
+		}
+		if info.Size == 0 {
+			t.Fatalf("backup %d is empty", i)
+		}
+		created = append(created, info.Path)
+	}
+
+	list, err := backup.List(backups)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list) != 2 || list[0] != created[1] || list[1] != created[2] {
+		t.Fatalf("expected the two newest backups %v, got %v", created[1:], list)
+	}
+
+	err = store.InsertBenchmarks([]types.Benchmark{
+		{Repo: "backup/repo", Branch: "main", CommitHash: "c2", Component: "parse", Value: 20, Timestamp: 2},
+	})
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	store.Close()
+
+	previous, err := backup.Restore(list[1], target)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if _, err := os.Stat(previous); err != nil {
+		t.Fatalf("expected previous database at %s: %v", previous, err)
+	}
+
+	db, err = database.Open(config.DatabaseConfig{Driver: database.DriverSQLite, Path: target})
+	if err != nil {
+		t.Fatalf("failed to open restored database: %v", err)
+	}
+	restored := storage.New(db)
+	defer restored.Close()
+
+	recent, err := restored.RecentBenchmarks("backup/repo", "parse", types.DefaultMetric, 10)
+	if err != nil || len(recent) != 1 || recent[0].CommitHash != "c1" {
+		t.Fatalf("expected only the backed-up sample from c1, got %v (%v)", recent, err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.db")
+	if err := os.WriteFile(corrupt, []byte("not a database"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backup.Restore(corrupt, target); err == nil {
+		t.Fatal("expected restoring a corrupt backup to fail")
+	}
+}
+
+func TestRestoreSetsAsideStaleWAL(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "regression.db")
+
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: target})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+	db.MustExec(`PRAGMA journal_mode=WAL`)
+	insert := func(commit string) {
+		err := store.InsertBenchmarks([]types.Benchmark{
+			{Repo: "backup/repo", Branch: "main", CommitHash: commit, Component: "parse", Value: 10, Timestamp: 1},
+		})
+		if err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+	}
+	insert("c1")
+	info, err := backup.Create(store, filepath.Join(dir, "backups"), 1)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	// Leave the WAL behind as a crash would, holding a write the backup lacks.
+	db.MustExec(`PRAGMA wal_autocheckpoint=0`)
+	insert("c2")
+	wal, err := os.ReadFile(target + "-wal")
+	if err != nil || len(wal) == 0 {
+		t.Fatalf("expected a WAL with pending writes: %v", err)
+	}
+	store.Close()
+	if err := os.WriteFile(target+"-wal", wal, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := backup.Restore(info.Path, target)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if _, err := os.Stat(target + "-wal"); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale WAL to be moved away, got %v", err)
+	}
+	if _, err := os.Stat(previous + "-wal"); err != nil {
+		t.Fatalf("expected the WAL kept with the previous database: %v", err)
+	}
+
+	db, err = database.Open(config.DatabaseConfig{Driver: database.DriverSQLite, Path: target})
+	if err != nil {
+		t.Fatalf("failed to open restored database: %v", err)
+	}
+	restored := storage.New(db)
+	defer restored.Close()
+	recent, err := restored.RecentBenchmarks("backup/repo", "parse", types.DefaultMetric, 10)
+	if err != nil || len(recent) != 1 || recent[0].CommitHash != "c1" {
+		t.Fatalf("expected only the backed-up sample from c1, got %v (%v)", recent, err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+const baselinesPath = "/admin/repos/octo%2Fapp/baselines"
+
+func TestBaselineRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+
+	requests := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPost, baselinesPath + "/rebuild", nil},
+		{http.MethodDelete, baselinesPath + "/parse", nil},
+		{http.MethodPost, baselinesPath + "/parse/pin", types.PinBaselineRequest{Commit: "m1"}},
+	}
+	for _, r := range requests {
+		if rec := s.admin(r.method, r.path, r.body, false); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s %s without a token: expected 401, got %d", r.method, r.path, rec.Code)
+		}
+		if rec := s.admin(r.method, "/repos/octo%2Fapp/baselines"+r.path[len(baselinesPath):], r.body, false); rec.Code != http.StatusNotFound {
+			t.Fatalf("%s %s: expected the public route to be gone, got %d", r.method, r.path, rec.Code)
+		}
+	}
+
+	if rec := s.admin(http.MethodDelete, baselinesPath+"/parse", nil, true); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected reset with the token to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRebuildKeepsPinnedBaselines(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+
+	rec := s.admin(http.MethodPost, baselinesPath+"/parse/pin", types.PinBaselineRequest{Commit: "m1"}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pin failed: %d %s", rec.Code, rec.Body)
+	}
+	for i := 7; i <= 12; i++ {
+		s.analyze(fmt.Sprintf("m%d", i), 0, 10.5)
+	}
+
+	var result struct {
+		Baselines []types.Baseline `json:"baselines"`
+		Pinned    []types.Baseline `json:"pinned"`
+	}
+	rec = s.admin(http.MethodPost, baselinesPath+"/rebuild", nil, true)
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("rebuild failed: %d %s", rec.Code, rec.Body)
+	}
+	if len(result.Baselines) != 0 || len(result.Pinned) != 1 || result.Pinned[0].BaselineValue != 10 {
+		t.Fatalf("expected the pinned baseline to be kept at 10, got %+v", result)
+	}
+	if source := baselineSource(t, s); source != regression.BaselineSourcePinned {
+		t.Fatalf("expected the baseline to stay pinned, got %s", source)
+	}
+
+	rec = s.admin(http.MethodPost, baselinesPath+"/rebuild?force", nil, true)
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("forced rebuild failed: %d %s", rec.Code, rec.Body)
+	}
+	if len(result.Baselines) != 1 || len(result.Pinned) != 0 {
+		t.Fatalf("expected force to rebuild the pinned baseline, got %+v", result)
+	}
+	if source := baselineSource(t, s); source != regression.BaselineSourceRebuilt {
+		t.Fatalf("expected a rebuilt baseline, got %s", source)
+	}
+}
+
+func baselineSource(t *testing.T, s *testServer) string {
+	t.Helper()
+	rec := s.admin(http.MethodGet, "/repos/octo%2Fapp/baselines/parse", nil, false)
+	var provenance types.BaselineProvenance
+	if err := json.Unmarshal(rec.Body.Bytes(), &provenance); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("baseline lookup failed: %d %s", rec.Code, rec.Body)
+	}
+	return provenance.Source
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func newBisection(candidates ...string) *types.Bisection {
+	return &types.Bisection{
+		GoodValue:  10,
+		BadValue:   20,
+		Candidates: candidates,
+		High:       len(candidates) - 1,
+		Status:     regression.BisectionRunning,
+	}
+}
+
+func TestBisectionSteps(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		samples    []float64
+		visited    []string
+		culprit    string
+	}{
+		{"culprit in the middle", []string{"a", "b", "c", "d", "e"}, []float64{19, 11}, []string{"c", "b"}, "c"},
+		{"culprit first", []string{"a", "b", "c", "d", "e"}, []float64{20, 20, 20}, []string{"c", "b", "a"}, "a"},
+		{"culprit is the bad commit", []string{"a", "b", "c", "d", "e"}, []float64{10, 12}, []string{"c", "d"}, "e"},
+		{"two candidates", []string{"a", "b"}, []float64{10}, []string{"a"}, "b"},
+		{"ties count as good", []string{"a", "b"}, []float64{15}, []string{"a"}, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBisection(tt.candidates...)
+			for i, value := range tt.samples {
+				next := regression.NextBisectionCommit(b)
+				if next != tt.visited[i] {
+					t.Fatalf("step %d: expected to measure %s, got %q", i, tt.visited[i], next)
+				}
+				b.PendingCommit = next
+				regression.RecordBisectionSample(b, value)
+			}
+
+			if b.Status != regression.BisectionComplete || b.Culprit != tt.culprit || b.PendingCommit != "" {
+				t.Fatalf("expected completion at %s, got status %s culprit %q pending %q",
+					tt.culprit, b.Status, b.Culprit, b.PendingCommit)
+			}
+			if next := regression.NextBisectionCommit(b); next != "" {
+				t.Fatalf("expected nothing left to measure, got %s", next)
+			}
+		})
+	}
+}
+
+func TestBisectionRunsStayOutOfHistory(t *testing.T) {
+	store, detector := newDetector(t)
+	repo := "bisect/repo"
+
+	for i := 0; i < 6; i++ {
+		analyze(t, detector, repo, fmt.Sprintf("good%d", i), 10)
+	}
+
+	response, err := detector.Analyze(types.AnalyzeRequest{
+		Repo: repo, Branch: "main", Commit: "midpoint", BisectionID: 7,
+		Components: map[string]float64{"parse": 50},
+	})
+	if err != nil {
+		t.Fatalf("bisection analyze failed: %v", err)
+	}
+	if result := response.Components[0].Result; result == nil || !result.IsRegression {
+		t.Fatalf("expected the midpoint to be measured against the baseline, got %+v", response.Components[0])
+	}
+
+	run, err := store.Run(response.RunID)
+	if err != nil || run.BisectionID != 7 {
+		t.Fatalf("expected the run to record bisection 7, got %+v (%v)", run, err)
+	}
+
+	baseline, err := store.Baseline(repo, "parse", types.DefaultMetric)
+	if err != nil || baseline.BaselineValue != 10 {
+		t.Fatalf("expected the baseline to stay at 10, got %+v (%v)", baseline, err)
+	}
+
+	recent, err := store.RecentBenchmarks(repo, "parse", types.DefaultMetric, 50)
+	if err != nil || len(recent) != 6 {
+		t.Fatalf("expected only the 6 regular samples in history, got %v (%v)", recent, err)
+	}
+
+	last, err := store.LastBenchmarkBefore(repo, "main", "parse", types.DefaultMetric, "next")
+	if err != nil || last.CommitHash != "good5" {
+		t.Fatalf("expected good5 as the last good sample, got %+v (%v)", last, err)
+	}
+
+	if _, err := store.LatestCommitRun(repo, "midpoint"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected bisection runs to be skipped as a commit's latest run, got %v", err)
+	}
+
+	response, err = detector.Analyze(types.AnalyzeRequest{
+		Repo: repo, Branch: "main", Commit: "midpoint", BisectionID: 7,
+		Components: map[string]float64{"render": 5},
+	})
+	if err != nil {
+		t.Fatalf("bisection analyze failed: %v", err)
+	}
+	if component := response.Components[0]; component.Error != regression.ErrBaselineNotFound.Error() {
+		t.Fatalf("expected a bisection run not to seed a baseline, got %+v", component)
+	}
+	if _, err := store.Baseline(repo, "render", types.DefaultMetric); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected no render baseline, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"testing"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/regression"
+	"regression-ci/pkg/types"
+)
+
+func TestCompareVerdicts(t *testing.T) {
+	before := []types.Measurement{
+		{Component: "parse", Samples: []float64{10, 10.2, 9.8}},
+		{Component: "render", Samples: []float64{10}},
+		{Component: "throughput", Metric: "ops/s", Direction: types.DirectionHigher, Samples: []float64{100}},
+		{Component: "legacy", Samples: []float64{5}},
+		{Component: "skipped", Samples: []float64{1}},
+	}
+	after := []types.Measurement{
+		{Component: "parse", Samples: []float64{20, 20.2, 19.8}},
+		{Component: "render", Samples: []float64{5}},
+		{Component: "throughput", Metric: "ops/s", Direction: types.DirectionHigher, Samples: []float64{102}},
+		{Component: "fresh", Samples: []float64{1}},
+		{Component: "skipped", Samples: []float64{50}},
+	}
+	disabled := false
+	file := &config.RepoFile{Components: map[string]config.RepoFileComponent{"skipped": {Enabled: &disabled}}}
+
+	want := map[string]string{
+		"parse":      regression.VerdictRegression,
+		"render":     regression.VerdictImprovement,
+		"throughput": regression.VerdictUnchanged,
+		"fresh":      regression.VerdictAdded,
+		"legacy":     regression.VerdictRemoved,
+	}
+
+	comparisons := regression.Compare(config.DefaultDetection(), file, before, after)
+	if len(comparisons) != len(want) {
+		t.Fatalf("expected %d comparisons, got %+v", len(want), comparisons)
+	}
+	for _, c := range comparisons {
+		if c.Verdict != want[c.Component] {
+			t.Errorf("%s: expected %s, got %s (%+v)", c.Component, want[c.Component], c.Verdict, c.Result)
+		}
+		if (c.Result == nil) != (c.Verdict == regression.VerdictAdded || c.Verdict == regression.VerdictRemoved) {
+			t.Errorf("%s: expected a result only for metrics in both sets, got %+v", c.Component, c.Result)
+		}
+	}
+}
+
+func TestCompareDisabledRepoFile(t *testing.T) {
+	disabled := false
+	file := &config.RepoFile{Enabled: &disabled}
+	measurements := []types.Measurement{{Component: "parse", Samples: []float64{10}}}
+
+	if comparisons := regression.Compare(config.DefaultDetection(), file, measurements, measurements); len(comparisons) != 0 {
+		t.Fatalf("expected a disabled repository file to compare nothing, got %+v", comparisons)
+	}
+}
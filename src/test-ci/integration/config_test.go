@@ -0,0 +1,257 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/server"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func validConfig() *config.Config {
+	return &config.Config{
+		Server:    config.ServerConfig{Address: ":8080", ReadTimeout: time.Second, WriteTimeout: time.Second},
+		Database:  config.DatabaseConfig{Driver: "sqlite", Path: "regression.db"},
+		Detection: config.DefaultDetection(),
+		Logging:   config.LoggingConfig{Level: "info"},
+		Retention: config.RetentionConfig{Granularity: "commit", Interval: time.Hour},
+		Backup:    config.BackupConfig{Dir: "backups", Keep: 7},
+		Ingest:    config.IngestConfig{MaxBodyBytes: 1 << 20},
+		Artifacts: config.ArtifactsConfig{Dir: "artifacts"},
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("expected the base config to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(c *config.Config)
+		want   string
+	}{
+		{"empty address", func(c *config.Config) { c.Server.Address = "" }, "server.address"},
+		{"read timeout", func(c *config.Config) { c.Server.ReadTimeout = 0 }, "server.read_timeout"},
+		{"write timeout", func(c *config.Config) { c.Server.WriteTimeout = -time.Second }, "server.write_timeout"},
+		{"sqlite without path", func(c *config.Config) { c.Database.Path = "" }, "database.path"},
+		{"postgres without dsn", func(c *config.Config) { c.Database.Driver = "postgres" }, "database.dsn"},
+		{"unknown driver", func(c *config.Config) { c.Database.Driver = "mysql" }, "database.driver"},
+		{"app id without key", func(c *config.Config) { c.GitHub.AppID = 1 }, "github.app_id"},
+		{"relative api url", func(c *config.Config) { c.GitHub.APIURL = "github.local" }, "github.api_url"},
+		{"default threshold", func(c *config.Config) { c.Detection.DefaultThreshold = 0 }, "detection.default_threshold"},
+		{"absolute threshold", func(c *config.Config) { c.Detection.AbsoluteThreshold = -1 }, "detection.absolute_threshold"},
+		{"strict factor", func(c *config.Config) { c.Detection.StrictFactor = 2 }, "detection.strict_factor"},
+		{"min samples", func(c *config.Config) { c.Detection.MinSamples = 0 }, "detection.min_samples"},
+		{"max below min samples", func(c *config.Config) { c.Detection.MaxSamples = 2 }, "detection.max_samples"},
+		{"significance level", func(c *config.Config) { c.Detection.SignificanceLevel = 1 }, "detection.significance_level"},
+		{"negative raw days", func(c *config.Config) { c.Retention.RawDays = -1 }, "retention.raw_days"},
+		{"retention granularity", func(c *config.Config) { c.Retention.RawDays, c.Retention.Granularity = 30, "week" }, "retention.granularity"},
+		{"retention interval", func(c *config.Config) { c.Retention.RawDays, c.Retention.Interval = 30, 0 }, "retention.interval"},
+		{"vacuum interval", func(c *config.Config) { c.Retention.RawDays, c.Retention.VacuumInterval = 30, -time.Hour }, "retention.vacuum_interval"},
+		{"backup interval", func(c *config.Config) { c.Backup.Interval = -time.Hour }, "backup.interval"},
+		{"backup keep", func(c *config.Config) { c.Backup.Keep = -1 }, "backup.keep"},
+		{"backup without dir", func(c *config.Config) { c.Backup.Interval, c.Backup.Dir = time.Hour, "" }, "backup.dir"},
+		{"backup of postgres", func(c *config.Config) {
+			c.Backup.Interval = time.Hour
+			c.Database.Driver, c.Database.DSN = "postgres", "postgres://db"
+		}, "pg_dump"},
+		{"body limit", func(c *config.Config) { c.Ingest.MaxBodyBytes = 0 }, "ingest.max_body_bytes"},
+		{"artifacts dir", func(c *config.Config) { c.Artifacts.Dir = "" }, "artifacts.dir"},
+		{"log level", func(c *config.Config) { c.Logging.Level = "loud" }, "logging.level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected a problem with %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	cfg := validConfig()
+	cfg.Server.Address, cfg.Artifacts.Dir = "", ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "server.address") ||
+		!strings.Contains(err.Error(), "artifacts.dir") {
+		t.Fatalf("expected every problem to be reported at once, got %v", err)
+	}
+}
+
+// loadConfig runs config.Load from an empty directory with a fresh viper.
+func loadConfig(t *testing.T) (*config.Config, error) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return config.Load()
+}
+
+func TestConfigEnvironment(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "admin-token")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(c *config.Config) bool
+		wantErr string
+	}{
+		{"defaults", nil, func(c *config.Config) bool {
+			return c.Server.Address == ":8080" && c.Detection.DefaultThreshold == 10
+		}, ""},
+		{"overrides", map[string]string{
+			"REGRESSION_CI_SERVER_ADDRESS":              ":9090",
+			"REGRESSION_CI_DETECTION_DEFAULT_THRESHOLD": "15",
+			"REGRESSION_CI_RETENTION_INTERVAL":          "2h",
+			"REGRESSION_CI_GITHUB_WEBHOOK_SECRET":       "shh",
+			"REGRESSION_CI_INGEST_MAX_BODY_BYTES":       "1024",
+		}, func(c *config.Config) bool {
+			return c.Server.Address == ":9090" && c.Detection.DefaultThreshold == 15 &&
+				c.Retention.Interval == 2*time.Hour && c.GitHub.WebhookSecret == "shh" && c.Ingest.MaxBodyBytes == 1024
+		}, ""},
+		{"file takes precedence", map[string]string{
+			"REGRESSION_CI_ADMIN_TOKEN":      "inline",
+			"REGRESSION_CI_ADMIN_TOKEN_FILE": secret,
+		}, func(c *config.Config) bool { return c.Admin.Token == "from-file" }, ""},
+		{"unreadable file", map[string]string{
+			"REGRESSION_CI_GITHUB_TOKEN_FILE": filepath.Join(t.TempDir(), "missing"),
+		}, nil, "github.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := loadConfig(t)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error naming %s, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || !tt.check(cfg) {
+				t.Fatalf("unexpected config %+v (%v)", cfg, err)
+			}
+		})
+	}
+}
+
+func newReloadServer(t *testing.T) *server.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+	t.Cleanup(func() { store.Close() })
+
+	cfg := validConfig()
+	cfg.Admin.Token = "old-token"
+	return server.New(store, cfg)
+}
+
+func TestConfigReload(t *testing.T) {
+	t.Run("applies live settings", func(t *testing.T) {
+		srv := newReloadServer(t)
+		updated := validConfig()
+		updated.Detection.DefaultThreshold = 20
+		updated.Admin.Token = "new-token"
+
+		changes, err := srv.ApplyConfig(updated)
+		if err != nil || len(changes) != 2 {
+			t.Fatalf("expected two changes, got %v (%v)", changes, err)
+		}
+		for _, change := range changes {
+			if change.Restart {
+				t.Fatalf("expected %s to apply without a restart", change.Key)
+			}
+			if change.Key == "admin.token" && (change.Old == "old-token" || change.New == "new-token") {
+				t.Fatalf("expected the token to be redacted, got %v", change)
+			}
+		}
+		if cfg := srv.Config(); cfg.Detection.DefaultThreshold != 20 || cfg.Admin.Token != "new-token" {
+			t.Fatalf("expected the reload to take effect, got %+v", cfg)
+		}
+
+		// The detector sees the new threshold too.
+		router := srv.Router()
+		var result *types.RegressionResult
+		for _, commit := range []string{"m1", "m2", "m3", "m4", "m5", "m6", "m7"} {
+			body, _ := json.Marshal(types.AnalyzeRequest{Repo: "octo/app", Branch: "main", Commit: commit,
+				Components: map[string]float64{"parse": 10}})
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/analyze", bytes.NewReader(body)))
+			var resp types.AnalyzeResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			result = resp.Components[0].Result
+		}
+		if result == nil || result.Threshold != 20 {
+			t.Fatalf("expected analyses to use the reloaded threshold, got %+v", result)
+		}
+	})
+
+	t.Run("reports restart-only settings without applying them", func(t *testing.T) {
+		srv := newReloadServer(t)
+		updated := validConfig()
+		updated.Admin.Token = "old-token"
+		updated.Server.Address = ":9090"
+		updated.Database.Path = "other.db"
+
+		changes, err := srv.ApplyConfig(updated)
+		if err != nil || len(changes) != 2 {
+			t.Fatalf("expected two changes, got %v (%v)", changes, err)
+		}
+		for _, change := range changes {
+			if !change.Restart {
+				t.Fatalf("expected %s to require a restart", change.Key)
+			}
+		}
+		if cfg := srv.Config(); cfg.Server.Address != ":8080" || cfg.Database.Path != "regression.db" {
+			t.Fatalf("expected restart-only settings to keep their running values, got %+v %+v", cfg.Server, cfg.Database)
+		}
+	})
+
+	t.Run("keeps the old config when the reload is invalid", func(t *testing.T) {
+		srv := newReloadServer(t)
+		updated := validConfig()
+		updated.Admin.Token = "new-token"
+		updated.Detection.DefaultThreshold = 0
+
+		if _, err := srv.ApplyConfig(updated); err == nil {
+			t.Fatal("expected an invalid reload to be rejected")
+		}
+		if cfg := srv.Config(); cfg.Admin.Token != "old-token" || cfg.Detection.DefaultThreshold != 10 {
+			t.Fatalf("expected the previous config to stay in effect, got %+v", cfg)
+		}
+	})
+}
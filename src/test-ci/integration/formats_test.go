@@ -0,0 +1,165 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"regression-ci/internal/formats"
+	"regression-ci/pkg/types"
+)
+
+// UpdateGoldenEnv rewrites the expected outputs under test-ci/golden from
+// the current parsers instead of comparing against them.
+const UpdateGoldenEnv = "REGRESSION_CI_UPDATE_GOLDEN"
+
+const goldenSuffix = ".golden"
+
+type goldenResult struct {
+	Format       string              `json:"format"`
+	Measurements []types.Measurement `json:"measurements"`
+	Metadata     types.Metadata      `json:"metadata"`
+}
+
+// TestFormatGolden parses every input under test-ci/golden/<format>/ and
+// compares the result with the <input>.golden file next to it. Inputs must
+// also be recognised by sniffing alone. Directories are uploaded as tar
+// archives, the way Criterion results are. Every registered format needs
+// at least one case.
+func TestFormatGolden(t *testing.T) {
+	root := goldenRoot(t)
+	update := os.Getenv(UpdateGoldenEnv) != ""
+
+	registered := make(map[string]bool)
+	for _, format := range formats.All() {
+		registered[format.Name] = true
+
+		entries, err := os.ReadDir(filepath.Join(root, format.Name))
+		if err != nil {
+			t.Errorf("format %s has no golden cases: %v", format.Name, err)
+			continue
+		}
+
+		cases := 0
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), goldenSuffix) {
+				continue
+			}
+			cases++
+
+			name := format.Name + "/" + entry.Name()
+			path := filepath.Join(root, format.Name, entry.Name())
+			t.Run(name, func(t *testing.T) {
+				input := goldenInput(t, path)
+
+				detected, _, err := formats.Detect("", bytes.NewReader(input))
+				if err != nil || detected != format.Name {
+					t.Errorf("expected input to be detected as %s, got %q (%v)", format.Name, detected, err)
+				}
+
+				result, err := formats.Parse(format.Name, "", bytes.NewReader(input))
+				if err != nil {
+					t.Fatalf("parse failed: %v", err)
+				}
+
+				got, err := json.MarshalIndent(goldenResult{format.Name, result.Measurements, result.Metadata}, "", "  ")
+				if err != nil {
+					t.Fatalf("failed to encode result: %v", err)
+				}
+				got = append(got, '\n')
+
+				if update {
+					if err := os.WriteFile(path+goldenSuffix, got, 0644); err != nil {
+						t.Fatalf("failed to update golden file: %v", err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(path + goldenSuffix)
+				if err != nil {
+					t.Fatalf("missing golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("result differs from %s%s:\n%s", entry.Name(), goldenSuffix, got)
+				}
+			})
+		}
+		if cases == 0 {
+			t.Errorf("format %s has no golden cases", format.Name)
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("failed to list golden cases: %v", err)
+	}
+	for _, entry := range entries {
+		if !registered[entry.Name()] {
+			t.Errorf("golden cases for unregistered format %s", entry.Name())
+		}
+	}
+}
+
+func goldenRoot(t *testing.T) string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("failed to locate golden files")
+	}
+	return filepath.Join(filepath.Dir(file), "..", "golden")
+}
+
+// goldenInput reads a case, packing directories into a tar archive rooted
+// at the directory's name.
+func goldenInput(t *testing.T, path string) []byte {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read input: %v", err)
+		}
+		return data
+	}
+
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	base := filepath.Dir(path)
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{Name: filepath.ToSlash(rel), Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = archive.Write(data)
+		return err
+	})
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		t.Fatalf("failed to archive input: %v", err)
+	}
+
+	return buf.Bytes()
+}
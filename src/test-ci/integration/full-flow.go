@@ -15,10 +15,11 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/glebarez/go-sqlite"
 
 	"regression-ci/internal/config"
+	"regression-ci/internal/database"
 	"regression-ci/internal/server"
+	"regression-ci/internal/storage"
 	"regression-ci/pkg/types"
 )
 
@@ -29,7 +30,7 @@ type TestEnvironment struct {
 }
 
 func SetupTestEnv(t *testing.T) *TestEnvironment {
-	db, err := sqlx.Open("sqlite", ":memory:")
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
@@ -41,7 +42,8 @@ func SetupTestEnv(t *testing.T) *TestEnvironment {
 			WriteTimeout: 30 * time.Second,
 		},
 		Database: config.DatabaseConfig{
-			Path: ":memory:",
+			Driver: database.DriverSQLite,
+			Path:   ":memory:",
 		},
 		Detection: config.DetectionConfig{
 			DefaultThreshold: 10.0,
@@ -50,7 +52,7 @@ func SetupTestEnv(t *testing.T) *TestEnvironment {
 		},
 	}
 
-	srv := server.New(db, cfg)
+	srv := server.New(storage.New(db), cfg)
 	testServer := httptest.NewServer(srv.Router())
 
 	return &TestEnvironment{
@@ -0,0 +1,76 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/github"
+)
+
+// compareServer serves total commits c0..c<total-1> in pages of perPage, but
+// lists no more than listed of them, as GitHub does past its cap.
+func compareServer(t *testing.T, total, listed, perPage int) *github.Client {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		commits := []map[string]string{}
+		for i := (page - 1) * perPage; i < page*perPage && i < listed; i++ {
+			commits = append(commits, map[string]string{"sha": fmt.Sprintf("c%d", i)})
+		}
+		if page*perPage < listed {
+			next := *r.URL
+			query := next.Query()
+			query.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = query.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"total_commits": total, "commits": commits})
+	}))
+	t.Cleanup(api.Close)
+
+	return github.New(config.GitHubConfig{APIURL: api.URL})
+}
+
+func TestCompareCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		listed  int
+		perPage int
+		wantErr bool
+	}{
+		{"single page", 3, 3, 100, false},
+		{"several pages", 250, 250, 100, false},
+		{"truncated by the compare cap", 300, 250, 250, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := compareServer(t, tt.total, tt.listed, tt.perPage)
+			shas, err := client.CompareCommits(context.Background(), "octo", "app", "good", "bad")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected a truncated range to fail, got %d commits", len(shas))
+				}
+				return
+			}
+			if err != nil || len(shas) != tt.total || shas[len(shas)-1] != fmt.Sprintf("c%d", tt.total-1) {
+				t.Fatalf("expected all %d commits in order, got %d (%v)", tt.total, len(shas), err)
+			}
+		})
+	}
+}
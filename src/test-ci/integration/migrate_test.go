@@ -0,0 +1,92 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+)
+
+func openSQLite(t *testing.T, path string) *sqlx.DB {
+	t.Helper()
+	db, err := database.Open(config.DatabaseConfig{Driver: database.DriverSQLite, Path: path})
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateUpToDateIsNoop(t *testing.T) {
+	db := openSQLite(t, ":memory:")
+	latest, err := database.LatestVersion()
+	if err != nil {
+		t.Fatalf("failed to read migrations: %v", err)
+	}
+
+	applied, err := database.Migrate(db)
+	if err != nil || len(applied) == 0 || applied[len(applied)-1].Version != latest {
+		t.Fatalf("expected every migration up to %d, got %d (%v)", latest, len(applied), err)
+	}
+
+	applied, err = database.Migrate(db)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected nothing to apply on an up-to-date database, got %v (%v)", applied, err)
+	}
+	if version, err := database.SchemaVersion(db); err != nil || version != latest {
+		t.Fatalf("expected version %d, got %d (%v)", latest, version, err)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db := openSQLite(t, ":memory:")
+	if _, err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	latest, _ := database.LatestVersion()
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', 0)`, latest+1); err != nil {
+		t.Fatalf("failed to record a future migration: %v", err)
+	}
+
+	if _, err := database.Migrate(db); !errors.Is(err, database.ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestMigrateResumesPartialDatabase(t *testing.T) {
+	migrations, err := database.Migrations()
+	if err != nil || len(migrations) < 4 {
+		t.Fatalf("expected embedded migrations, got %d (%v)", len(migrations), err)
+	}
+	path := filepath.Join(t.TempDir(), "partial.db")
+
+	// Apply the first three the way an older binary would have.
+	db := openSQLite(t, path)
+	db.MustExec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at BIGINT NOT NULL)`)
+	for _, migration := range migrations[:3] {
+		db.MustExec(strings.ReplaceAll(migration.SQL, "{{serial}}", "INTEGER PRIMARY KEY"))
+		db.MustExec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, 0)`,
+			migration.Version, migration.Name)
+	}
+	db.Close()
+
+	db = openSQLite(t, path)
+	applied, err := database.Migrate(db)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if len(applied) != len(migrations)-3 || applied[0].Version != migrations[3].Version {
+		t.Fatalf("expected to resume at version %d, applied %v", migrations[3].Version, applied)
+	}
+	if version, _ := database.SchemaVersion(db); version != migrations[len(migrations)-1].Version {
+		t.Fatalf("expected the latest version, got %d", version)
+	}
+}
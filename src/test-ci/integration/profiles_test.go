@@ -0,0 +1,75 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/google/pprof/profile"
+
+	"regression-ci/internal/profiles"
+)
+
+// cpuProfile builds a CPU profile with the given nanoseconds spent in each
+// function, the way runtime/pprof lays one out.
+func cpuProfile(flat map[string]int64) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     10000000,
+	}
+	var id uint64
+	for name, ns := range flat {
+		id++
+		function := &profile.Function{ID: id, Name: name}
+		location := &profile.Location{ID: id, Line: []profile.Line{{Function: function}}}
+		p.Function = append(p.Function, function)
+		p.Location = append(p.Location, location)
+		p.Sample = append(p.Sample, &profile.Sample{
+			Location: []*profile.Location{location},
+			Value:    []int64{ns / p.Period, ns},
+		})
+	}
+	return p
+}
+
+func TestProfileDiff(t *testing.T) {
+	base := cpuProfile(map[string]int64{"parse": 600, "lex": 300, "alloc": 100})
+	current := cpuProfile(map[string]int64{"parse": 1200, "lex": 300, "alloc": 500, "regexp.Compile": 1000})
+
+	// Round-trip through the wire format, as uploads are.
+	var buf bytes.Buffer
+	if err := current.Write(&buf); err != nil {
+		t.Fatalf("failed to encode profile: %v", err)
+	}
+	parsed, err := profiles.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse profile: %v", err)
+	}
+	if kind := profiles.Kind(parsed); kind != "cpu" {
+		t.Fatalf("expected cpu kind, got %q", kind)
+	}
+
+	diff, err := profiles.Diff(base, parsed)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if diff.SampleType != "cpu" || diff.BaselineTotal != 1000 || diff.CurrentTotal != 3000 {
+		t.Fatalf("expected cpu totals 1000 and 3000, got %+v", diff)
+	}
+	if len(diff.Functions) != 4 || diff.Functions[0].Function != "regexp.Compile" {
+		t.Fatalf("expected regexp.Compile to have moved most, got %+v", diff.Functions)
+	}
+	for _, f := range diff.Functions {
+		if math.Abs(f.Delta-(f.Current-f.Baseline)) > 1e-9 {
+			t.Fatalf("delta of %s is not current minus baseline: %+v", f.Function, f)
+		}
+	}
+
+	if _, err := profiles.Parse([]byte("not a profile")); err == nil {
+		t.Fatal("expected garbage to be rejected")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/regression"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func newDetector(t *testing.T) (storage.Store, *regression.Detector) {
+	t.Helper()
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+	t.Cleanup(func() { store.Close() })
+	return store, regression.New(store, config.DefaultDetection())
+}
+
+func analyze(t *testing.T, detector *regression.Detector, repo, commit string, value float64) *types.AnalyzeResponse {
+	t.Helper()
+	response, err := detector.Analyze(types.AnalyzeRequest{
+		Repo: repo, Branch: "main", Commit: commit,
+		Components: map[string]float64{"parse": value},
+	})
+	if err != nil {
+		t.Fatalf("analyze %s failed: %v", commit, err)
+	}
+	return response
+}
+
+func TestReanalyzeAfterBaselineReset(t *testing.T) {
+	store, detector := newDetector(t)
+	repo := "reanalyze/repo"
+
+	for i := 0; i < 6; i++ {
+		analyze(t, detector, repo, fmt.Sprintf("good%d", i), 10)
+	}
+	response := analyze(t, detector, repo, "bad", 20)
+	if result := response.Components[0].Result; result == nil || !result.IsRegression {
+		t.Fatalf("expected bad to regress, got %+v", response.Components[0])
+	}
+
+	response, err := detector.Reanalyze(repo, "bad")
+	if err != nil {
+		t.Fatalf("reanalyze failed: %v", err)
+	}
+	if result := response.Components[0].Result; result == nil || !result.IsRegression || result.BaselineValue != 10 {
+		t.Fatalf("expected bad to still regress against 10, got %+v", response.Components[0])
+	}
+
+	if err := detector.ResetBaseline(repo, "parse", types.DefaultMetric); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+
+	response, err = detector.Reanalyze(repo, "bad")
+	if err != nil {
+		t.Fatalf("reanalyze failed: %v", err)
+	}
+	component := response.Components[0]
+	if component.Result != nil || component.Error != regression.ErrBaselineNotFound.Error() {
+		t.Fatalf("expected reanalysis without a baseline to report it, got %+v", component)
+	}
+
+	if _, err := store.Baseline(repo, "parse", types.DefaultMetric); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected reanalysis to leave the baseline unset, got %v", err)
+	}
+}
+
+func TestAnalysisRecordsBaselineSource(t *testing.T) {
+	_, detector := newDetector(t)
+	repo := "provenance/repo"
+
+	for i := 0; i < 6; i++ {
+		analyze(t, detector, repo, fmt.Sprintf("good%d", i), 10)
+	}
+	if _, err := detector.PinBaseline(repo, "parse", types.DefaultMetric, "good2"); err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	analyze(t, detector, repo, "head", 11)
+
+	response, err := detector.CommitAnalysis(repo, "head")
+	if err != nil {
+		t.Fatalf("commit analysis failed: %v", err)
+	}
+	result := response.Components[0].Result
+	if result == nil || result.BaselineSource != regression.BaselineSourcePinned || result.BaselineCommit != "good2" {
+		t.Fatalf("expected the stored verdict to name the pinned baseline, got %+v", response.Components[0])
+	}
+}
+
+func TestReanalyzeLeavesNewerPRHeadAlone(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+	s.analyze("head2", 5, 25)
+
+	before, err := s.store.PRReport("octo/app", 5)
+	if err != nil || before.HeadSHA != "head2" {
+		t.Fatalf("expected the report to follow head2, got %+v (%v)", before, err)
+	}
+	s.github.mu.Lock()
+	calls := len(s.github.calls)
+	s.github.mu.Unlock()
+
+	if rec := s.admin(http.MethodPost, "/admin/repos/octo%2Fapp/commits/head1/reanalyze", nil, true); rec.Code != http.StatusOK {
+		t.Fatalf("reanalyze failed: %d %s", rec.Code, rec.Body)
+	}
+
+	after, err := s.store.PRReport("octo/app", 5)
+	if err != nil || *after != *before {
+		t.Fatalf("expected the PR report to be unchanged, got %+v (%v)", after, err)
+	}
+	s.github.mu.Lock()
+	defer s.github.mu.Unlock()
+	if len(s.github.calls) != calls {
+		t.Fatalf("expected no GitHub calls for an old commit, got %v", s.github.calls[calls:])
+	}
+}
+
+func TestReanalyzeRepublishesPRHead(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+	updates := len(s.github.Calls(http.MethodPatch, "/check-runs/1"))
+
+	if rec := s.admin(http.MethodPost, "/admin/repos/octo%2Fapp/commits/head1/reanalyze", nil, true); rec.Code != http.StatusOK {
+		t.Fatalf("reanalyze failed: %d %s", rec.Code, rec.Body)
+	}
+	if len(s.github.Calls(http.MethodPatch, "/check-runs/1")) != updates+1 {
+		t.Fatal("expected reanalysing the head to update its check run")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"testing"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/regression"
+)
+
+func TestRepoFileFallbackStaysOnBranch(t *testing.T) {
+	_, detector := newDetector(t)
+	repo := "repofile/repo"
+
+	threshold := 25.0
+	mainFile := &config.RepoFile{Version: 1, ThresholdPercent: &threshold}
+	disabled := false
+	featureFile := &config.RepoFile{Version: 1, Enabled: &disabled}
+
+	err := detector.SaveRepoFile(repo, regression.RepoFileOrigin{Commit: "m1", Branch: "main", DefaultBranch: true,
+		Source: regression.RepoFileSourcePush}, mainFile, nil)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	err = detector.SaveRepoFile(repo, regression.RepoFileOrigin{Commit: "f1", Branch: "feature",
+		Source: regression.RepoFileSourcePush}, featureFile, nil)
+	if err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	tests := []struct {
+		branch  string
+		enabled bool
+	}{
+		{"main", true},
+		{"feature", false},
+		{"other", true},
+	}
+	for _, tt := range tests {
+		file, err := detector.RepoFile(repo, tt.branch, "uncached")
+		if err != nil || file == nil {
+			t.Fatalf("%s: expected a fallback file, got %v (%v)", tt.branch, file, err)
+		}
+		enabled := file.Enabled == nil || *file.Enabled
+		if enabled != tt.enabled {
+			t.Fatalf("%s: expected enabled=%v, got %+v", tt.branch, tt.enabled, file)
+		}
+		if tt.enabled && (file.ThresholdPercent == nil || *file.ThresholdPercent != threshold) {
+			t.Fatalf("%s: expected the default branch file, got %+v", tt.branch, file)
+		}
+	}
+
+	response := analyze(t, detector, repo, "m2", 10)
+	if len(response.Components) != 1 {
+		t.Fatalf("expected main to stay enabled despite the feature branch file, got %+v", response)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+func TestDownsampleBenchmarks(t *testing.T) {
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+	defer store.Close()
+	repo := "retention/repo"
+
+	// Commits interleave so every bucket spans several batches.
+	expired := make([]types.Benchmark, 2500)
+	for i := range expired {
+		expired[i] = types.Benchmark{Repo: repo, Branch: "main", CommitHash: fmt.Sprintf("c%d", i%5),
+			Component: "parse", Value: float64(i), Timestamp: int64(100 + i)}
+	}
+	expired = append(expired, types.Benchmark{Repo: repo, Branch: "main", CommitHash: "fresh",
+		Component: "parse", Value: 1, Timestamp: 5000})
+	if err := store.InsertBenchmarks(expired); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	baselineRun := &types.Run{Repo: repo, Branch: "main", CommitHash: "kept", StartedAt: 1, FinishedAt: 1}
+	if err := store.CreateRun(baselineRun, []types.Benchmark{
+		{Repo: repo, Branch: "main", CommitHash: "kept", Component: "parse", Value: 7, Timestamp: 1},
+	}); err != nil {
+		t.Fatalf("create run failed: %v", err)
+	}
+	samples, _ := store.RunBenchmarks(baselineRun.ID)
+	baseline := types.Baseline{Repo: repo, Component: "parse", Metric: types.DefaultMetric, BaselineValue: 7, SampleCount: 1}
+	if err := store.SaveBaseline(baseline, storage.BaselineSource{Source: "rolling", Estimator: "mean"}, samples); err != nil {
+		t.Fatalf("save baseline failed: %v", err)
+	}
+
+	bisectionRun := &types.Run{Repo: repo, Branch: "main", CommitHash: "mid", BisectionID: 7, StartedAt: 1, FinishedAt: 1}
+	if err := store.CreateRun(bisectionRun, []types.Benchmark{
+		{Repo: repo, Branch: "main", CommitHash: "mid", Component: "parse", Value: 9, Timestamp: 1},
+	}); err != nil {
+		t.Fatalf("create run failed: %v", err)
+	}
+
+	stats, err := store.DownsampleBenchmarks(4000, storage.GranularityCommit)
+	if err != nil || stats.SamplesRemoved != 2500 {
+		t.Fatalf("expected 2500 samples removed, got %+v (%v)", stats, err)
+	}
+
+	var aggregates []storage.BenchmarkAggregate
+	store.ExportAggregates(repo, func(agg storage.BenchmarkAggregate) error {
+		aggregates = append(aggregates, agg)
+		return nil
+	})
+	if len(aggregates) != 5 {
+		t.Fatalf("expected one aggregate per commit, got %d", len(aggregates))
+	}
+	for i, agg := range aggregates {
+		if agg.SampleCount != 500 || agg.MinValue != float64(i) || agg.MaxValue != float64(2495+i) ||
+			agg.FirstTimestamp != int64(100+i) || agg.LastTimestamp != int64(2595+i) {
+			t.Fatalf("expected bucket c%d to merge exactly across batches, got %+v", i, agg)
+		}
+	}
+
+	if stats, err := store.DownsampleBenchmarks(4000, storage.GranularityCommit); err != nil || stats.SamplesRemoved != 0 {
+		t.Fatalf("expected nothing left to downsample, got %+v (%v)", stats, err)
+	}
+	if kept, _ := store.BaselineSamples(repo, "parse", types.DefaultMetric); len(kept) != 1 {
+		t.Fatalf("expected the baseline sample to survive, got %v", kept)
+	}
+	if kept, _ := store.RunBenchmarks(bisectionRun.ID); len(kept) != 1 {
+		t.Fatalf("expected the bisection sample to survive, got %v", kept)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/server"
+	"regression-ci/internal/storage"
+	"regression-ci/pkg/types"
+)
+
+const (
+	testWebhookSecret = "webhook-secret"
+	testAdminToken    = "admin-token"
+)
+
+type githubCall struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// fakeGitHub answers the GitHub API calls the server makes and records them.
+// Users not listed in permissions have read access only.
+type fakeGitHub struct {
+	mu          sync.Mutex
+	calls       []githubCall
+	permissions map[string]string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/api/v3")
+
+	f.mu.Lock()
+	f.calls = append(f.calls, githubCall{Method: r.Method, Path: path, Body: string(body)})
+	permission := "read"
+	if strings.HasSuffix(path, "/permission") {
+		parts := strings.Split(path, "/")
+		if p, ok := f.permissions[parts[len(parts)-2]]; ok {
+			permission = p
+		}
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(path, "/permission"):
+		json.NewEncoder(w).Encode(map[string]string{"permission": permission})
+	case strings.Contains(path, "/contents/"):
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message": "Not Found"}`)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/comments"):
+		io.WriteString(w, `[]`)
+	case strings.Contains(path, "/labels"):
+		io.WriteString(w, `[]`)
+	default:
+		io.WriteString(w, `{"id": 1}`)
+	}
+}
+
+// Calls returns the recorded calls whose method and path match.
+func (f *fakeGitHub) Calls(method, pathSuffix string) []githubCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matched []githubCall
+	for _, call := range f.calls {
+		if call.Method == method && strings.HasSuffix(call.Path, pathSuffix) {
+			matched = append(matched, call)
+		}
+	}
+	return matched
+}
+
+type testServer struct {
+	t      *testing.T
+	store  storage.Store
+	router http.Handler
+	github *fakeGitHub
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.Init(config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	store := storage.New(db)
+	t.Cleanup(func() { store.Close() })
+
+	fake := &fakeGitHub{permissions: map[string]string{"maintainer": "write"}}
+	api := httptest.NewServer(fake)
+	t.Cleanup(api.Close)
+
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{Token: "token", WebhookSecret: testWebhookSecret, APIURL: api.URL},
+		Detection: config.DefaultDetection(),
+		Admin:     config.AdminConfig{Token: testAdminToken},
+		Ingest:    config.IngestConfig{MaxBodyBytes: 1 << 20},
+		Artifacts: config.ArtifactsConfig{Dir: t.TempDir()},
+	}
+
+	return &testServer{t: t, store: store, router: server.New(store, cfg).Router(), github: fake}
+}
+
+func (s *testServer) do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// admin sends an admin API request, with the token when authorized is set.
+func (s *testServer) admin(method, path string, body interface{}, authorized bool) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if authorized {
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	}
+	return s.do(req)
+}
+
+// analyze posts one value for the parse component and returns the verdict.
+func (s *testServer) analyze(commit string, prNumber int, value float64) *types.AnalyzeResponse {
+	s.t.Helper()
+	branch := "main"
+	if prNumber > 0 {
+		branch = "feature"
+	}
+	body, _ := json.Marshal(types.AnalyzeRequest{
+		Repo: "octo/app", Branch: branch, Commit: commit, PRNumber: prNumber,
+		Components: map[string]float64{"parse": value},
+	})
+
+	rec := s.do(httptest.NewRequest(http.MethodPost, "/analyze", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		s.t.Fatalf("analyze %s: expected 200, got %d: %s", commit, rec.Code, rec.Body)
+	}
+
+	var resp types.AnalyzeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("analyze %s: %v", commit, err)
+	}
+	return &resp
+}
+
+// comment delivers a signed issue_comment webhook on PR 5.
+func (s *testServer) comment(author, body string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	payload, _ := json.Marshal(map[string]interface{}{
+		"action":     "created",
+		"repository": map[string]interface{}{"full_name": "octo/app"},
+		"issue": map[string]interface{}{
+			"number":       5,
+			"pull_request": map[string]interface{}{"url": "https://example.test/pulls/5"},
+		},
+		"comment": map[string]interface{}{"body": body, "user": map[string]interface{}{"login": author}},
+	})
+
+	return s.webhook("issue_comment", payload)
+}
+
+// label delivers a signed pull_request webhook for PR 5 at head1 adding
+// label, with labels as the PR's full set afterwards.
+func (s *testServer) label(action, label string, labels ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	names := make([]map[string]string, len(labels))
+	for i, name := range labels {
+		names[i] = map[string]string{"name": name}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"action":     action,
+		"repository": map[string]interface{}{"full_name": "octo/app"},
+		"label":      map[string]string{"name": label},
+		"pull_request": map[string]interface{}{
+			"number": 5,
+			"head":   map[string]string{"sha": "head1"},
+			"base":   map[string]string{"ref": "main"},
+			"labels": names,
+		},
+	})
+
+	return s.webhook("pull_request", payload)
+}
+
+func (s *testServer) webhook(event string, payload []byte) *httptest.ResponseRecorder {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return s.do(req)
+}
+
+// seedBaseline gives parse a stable baseline of 10 on main.
+func (s *testServer) seedBaseline() {
+	for _, commit := range []string{"m1", "m2", "m3", "m4", "m5", "m6"} {
+		s.analyze(commit, 0, 10)
+	}
+}
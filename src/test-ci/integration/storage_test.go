@@ -0,0 +1,413 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"regression-ci/internal/config"
+	"regression-ci/internal/database"
+	"regression-ci/internal/storage"
+	"regression-ci/internal/transfer"
+	"regression-ci/pkg/types"
+)
+
+// PostgresDSNEnv points the storage suite at a disposable PostgreSQL
+// database; the Postgres run is skipped when it is unset.
+const PostgresDSNEnv = "REGRESSION_CI_TEST_POSTGRES_DSN"
+
+func TestStorageSQLite(t *testing.T) {
+	runStorageSuite(t, config.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"})
+}
+
+func TestStoragePostgres(t *testing.T) {
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", PostgresDSNEnv)
+	}
+	runStorageSuite(t, config.DatabaseConfig{Driver: database.DriverPostgres, DSN: dsn})
+}
+
+func runStorageSuite(t *testing.T, cfg config.DatabaseConfig) {
+	db, err := database.Init(cfg)
+	if err != nil {
+		t.Fatalf("failed to open %s database: %v", cfg.Driver, err)
+	}
+	store := storage.New(db)
+	defer store.Close()
+
+	// A unique repo keeps repeated runs against a shared Postgres apart.
+	repo := fmt.Sprintf("storage/%d", time.Now().UnixNano())
+
+	t.Run("benchmarks", func(t *testing.T) {
+		err := store.InsertBenchmarks([]types.Benchmark{
+			{Repo: repo, Branch: "main", CommitHash: "c1", Component: "parse", Value: 10, Timestamp: 1},
+			{Repo: repo, Branch: "main", CommitHash: "c2", Component: "parse", Value: 11, Timestamp: 2},
+			{Repo: repo, Branch: "main", CommitHash: "c2", Component: "render", Value: 5, Timestamp: 2},
+			{Repo: repo, Branch: "main", CommitHash: "c2", Component: "parse", Metric: "B/op", Value: 64, Timestamp: 2},
+		})
+		if err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+
+		recent, err := store.RecentBenchmarks(repo, "parse", types.DefaultMetric, 1)
+		if err != nil || len(recent) != 1 || recent[0].CommitHash != "c2" {
+			t.Fatalf("expected latest parse sample from c2, got %v (%v)", recent, err)
+		}
+
+		before, err := store.LastBenchmarkBefore(repo, "main", "parse", types.DefaultMetric, "c2")
+		if err != nil || before.CommitHash != "c1" {
+			t.Fatalf("expected c1 before c2, got %v (%v)", before, err)
+		}
+
+		series, err := store.BenchmarkSeries(repo)
+		if err != nil || len(series) != 3 || series[0].Metric != "B/op" {
+			t.Fatalf("expected 3 series starting with parse B/op, got %v (%v)", series, err)
+		}
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		run := &types.Run{Repo: repo, Branch: "main", CommitHash: "c3", Runner: "linux-x64",
+			StartedAt: 3, FinishedAt: 4, Metadata: types.Metadata{"os": "linux"}}
+		err := store.CreateRun(run, []types.Benchmark{
+			{Repo: repo, Branch: "main", CommitHash: "c3", Component: "parse", Value: 12, Timestamp: 3},
+		})
+		if err != nil || run.ID == 0 {
+			t.Fatalf("expected run to be created with an id, got %d (%v)", run.ID, err)
+		}
+		pValue := 0.01
+		err = store.SaveAnalysis(run.ID, []storage.AnalysisResult{
+			{Repo: repo, CommitHash: "c3", Component: "parse", IsRegression: true, CurrentValue: 12,
+				BaselineValue: 10, PValue: &pValue, CreatedAt: 4},
+			{Repo: repo, CommitHash: "c3", Component: "render", Error: "boom", CreatedAt: 4},
+		})
+		if err != nil {
+			t.Fatalf("save analysis failed: %v", err)
+		}
+
+		got, err := store.LatestCommitRun(repo, "c3")
+		if err != nil || got.ID != run.ID || got.Metadata["os"] != "linux" {
+			t.Fatalf("expected stored run, got %v (%v)", got, err)
+		}
+		results, err := store.RunAnalysis(run.ID)
+		if err != nil || len(results) != 2 || results[0].PValue == nil || results[1].PValue != nil {
+			t.Fatalf("expected two analysis rows with one p-value, got %v (%v)", results, err)
+		}
+		samples, err := store.RunBenchmarks(run.ID)
+		if err != nil || len(samples) != 1 || samples[0].RunID != run.ID {
+			t.Fatalf("expected one sample linked to the run, got %v (%v)", samples, err)
+		}
+		if _, err := store.Run(run.ID + 1000); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+
+		err = store.SaveArtifacts([]types.Artifact{
+			{RunID: run.ID, Repo: repo, Name: "cpu.pprof", Size: 42, SHA256: "ab", CreatedAt: 100},
+			{RunID: run.ID, Repo: repo, Name: "bench.log", ContentType: "text/plain", Size: 7, SHA256: "cd", CreatedAt: 100},
+			{RunID: run.ID, Repo: repo, Name: "parse.cpu.pprof", Component: "parse", Kind: "cpu", Size: 9, SHA256: "ef",
+				CreatedAt: 100},
+		})
+		if err != nil {
+			t.Fatalf("SaveArtifacts failed: %v", err)
+		}
+		artifacts, err := store.RunArtifacts(run.ID)
+		if err != nil || len(artifacts) != 3 || artifacts[0].Name != "bench.log" {
+			t.Fatalf("expected three artifacts ordered by name, got %v (%v)", artifacts, err)
+		}
+		artifact, err := store.Artifact(run.ID, "cpu.pprof")
+		if err != nil || artifact.Size != 42 {
+			t.Fatalf("expected cpu.pprof, got %v (%v)", artifact, err)
+		}
+		if _, err := store.Artifact(run.ID, "missing"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		profile, err := store.BaselineProfile(repo, "parse", types.DefaultMetric, "cpu", run.ID+1)
+		if err != nil || profile.Name != "parse.cpu.pprof" {
+			t.Fatalf("expected the earlier cpu profile, got %v (%v)", profile, err)
+		}
+		if _, err := store.BaselineProfile(repo, "parse", types.DefaultMetric, "cpu", run.ID); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected no profile before the run itself, got %v", err)
+		}
+	})
+
+	t.Run("baselines", func(t *testing.T) {
+		samples, err := store.CommitBenchmarks(repo, "parse", types.DefaultMetric, "c2")
+		if err != nil || len(samples) != 1 {
+			t.Fatalf("expected one c2 sample, got %v (%v)", samples, err)
+		}
+
+		baseline := types.Baseline{Repo: repo, Component: "parse", Metric: types.DefaultMetric, BaselineValue: 10,
+			SampleCount: 1, UpdatedAt: 1}
+		source := storage.BaselineSource{Source: "initial", Estimator: "mean"}
+		if err := store.SaveBaseline(baseline, source, samples); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+
+		baseline.BaselineValue = 11
+		source = storage.BaselineSource{Source: "pinned", Estimator: "mean", PinnedCommit: "c2"}
+		if err := store.SaveBaseline(baseline, source, samples); err != nil {
+			t.Fatalf("second save failed: %v", err)
+		}
+
+		got, err := store.Baseline(repo, "parse", types.DefaultMetric)
+		if err != nil || got.BaselineValue != 11 {
+			t.Fatalf("expected upserted baseline 11, got %v (%v)", got, err)
+		}
+		gotSource, err := store.BaselineSource(repo, "parse", types.DefaultMetric)
+		if err != nil || gotSource.PinnedCommit != "c2" {
+			t.Fatalf("expected pinned source, got %v (%v)", gotSource, err)
+		}
+		stored, err := store.BaselineSamples(repo, "parse", types.DefaultMetric)
+		if err != nil || len(stored) != 1 {
+			t.Fatalf("expected one baseline sample, got %v (%v)", stored, err)
+		}
+
+		if err := store.DeleteBaseline(repo, "parse", types.DefaultMetric); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		if err := store.DeleteBaseline(repo, "parse", types.DefaultMetric); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+		}
+		if _, err := store.Baseline(repo, "parse", types.DefaultMetric); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("repo_files", func(t *testing.T) {
+		file := storage.RepoFile{Repo: repo, CommitHash: "c1", Branch: "main", DefaultBranch: true, Source: "push",
+			Content: "{}", Problems: "[]", FetchedAt: 1}
+		if err := store.SaveRepoFile(file); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		file.FetchedAt = 2
+		file.Branch, file.DefaultBranch = "", false
+		if err := store.SaveRepoFile(file); err != nil {
+			t.Fatalf("upsert failed: %v", err)
+		}
+
+		latest, err := store.LatestRepoFile(repo, "main", "push")
+		if err != nil || latest.FetchedAt != 2 || !latest.DefaultBranch {
+			t.Fatalf("expected upserted repo file to keep its branch, got %v (%v)", latest, err)
+		}
+		feature := storage.RepoFile{Repo: repo, CommitHash: "f1", Branch: "feature", Source: "push",
+			Content: "{}", Problems: "[]", FetchedAt: 3}
+		if err := store.SaveRepoFile(feature); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		latest, err = store.LatestDefaultBranchRepoFile(repo, "push")
+		if err != nil || latest.CommitHash != "c1" {
+			t.Fatalf("expected the default branch file from c1, got %v (%v)", latest, err)
+		}
+		if _, err := store.RepoFile(repo, "missing"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("pr_state", func(t *testing.T) {
+		report := types.PRReport{Repo: repo, PRNumber: 7, HeadSHA: "c1", Response: "{}", UpdatedAt: 1}
+		if err := store.SavePRReport(report); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		if err := store.SetCheckRunID(repo, 7, 1<<40); err != nil {
+			t.Fatalf("set check run failed: %v", err)
+		}
+
+		if err := store.SavePRReport(report); err != nil {
+			t.Fatalf("resave failed: %v", err)
+		}
+		got, err := store.PRReport(repo, 7)
+		if err != nil || got.CheckRunID != 1<<40 {
+			t.Fatalf("expected check run kept for same head, got %v (%v)", got, err)
+		}
+
+		report.HeadSHA = "c2"
+		if err := store.SavePRReport(report); err != nil {
+			t.Fatalf("save for new head failed: %v", err)
+		}
+		got, err = store.PRReport(repo, 7)
+		if err != nil || got.CheckRunID != 0 {
+			t.Fatalf("expected check run reset for new head, got %v (%v)", got, err)
+		}
+
+		if err := store.SetPRLabels(repo, 7, []string{"perf-strict", "perf-strict", "perf-accepted"}); err != nil {
+			t.Fatalf("set labels failed: %v", err)
+		}
+		labels, err := store.PRLabels(repo, 7)
+		if err != nil || len(labels) != 2 {
+			t.Fatalf("expected 2 labels, got %v (%v)", labels, err)
+		}
+
+		ack := types.Acknowledgement{Repo: repo, PRNumber: 7, Component: "parse", Action: "accept", Author: "octocat", CreatedAt: 1}
+		if err := store.AddAcknowledgement(ack); err != nil {
+			t.Fatalf("acknowledge failed: %v", err)
+		}
+		acks, err := store.Acknowledgements(repo, 7)
+		if err != nil || len(acks) != 1 || acks[0].ID == 0 {
+			t.Fatalf("expected one acknowledgement with an id, got %v (%v)", acks, err)
+		}
+	})
+
+	t.Run("jobs", func(t *testing.T) {
+		b := &types.Bisection{
+			Repo: repo, Branch: "main", Component: "parse", GoodCommit: "c1", BadCommit: "c4",
+			GoodValue: 10, BadValue: 20, Candidates: types.CommitList{"c2", "c3", "c4"},
+			Low: 0, High: 2, PendingCommit: "c3", Status: "running", CreatedAt: 1, UpdatedAt: 1,
+		}
+		if err := store.CreateBisection(b); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		if b.ID == 0 {
+			t.Fatal("expected bisection id to be assigned")
+		}
+
+		pending, err := store.PendingBisections(repo, "running", "c3")
+		if err != nil || len(pending) != 1 || len(pending[0].Candidates) != 3 {
+			t.Fatalf("expected one pending bisection, got %v (%v)", pending, err)
+		}
+
+		b.Status, b.Culprit, b.PendingCommit = "complete", "c3", ""
+		if err := store.UpdateBisection(b); err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+
+		exists, err := store.HasBisection(repo, "c4")
+		if err != nil || !exists {
+			t.Fatalf("expected bisection for c4, got %v (%v)", exists, err)
+		}
+		all, err := store.Bisections(repo)
+		if err != nil || len(all) != 1 || all[0].Culprit != "c3" {
+			t.Fatalf("expected completed bisection, got %v (%v)", all, err)
+		}
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		for _, format := range []string{transfer.FormatJSONL, transfer.FormatCSV} {
+			var bundle bytes.Buffer
+			if err := transfer.Export(store, repo, format, &bundle); err != nil {
+				t.Fatalf("%s export failed: %v", format, err)
+			}
+
+			target := repo + "-" + format
+			for i := 0; i < 2; i++ {
+				result, err := transfer.Import(store, bytes.NewReader(bundle.Bytes()), target)
+				if err != nil {
+					t.Fatalf("%s import %d failed: %v", format, i, err)
+				}
+				imported := result.Tables["benchmarks"].Imported
+				if i == 0 && imported != 5 || i == 1 && imported != 0 {
+					t.Fatalf("%s import %d: expected 5 then 0 new samples, got %d", format, i, imported)
+				}
+			}
+
+			run, err := store.LatestCommitRun(target, "c3")
+			if err != nil || run.Metadata["os"] != "linux" {
+				t.Fatalf("expected imported run with metadata, got %v (%v)", run, err)
+			}
+			samples, err := store.RunBenchmarks(run.ID)
+			if err != nil || len(samples) != 1 || samples[0].Metric != types.DefaultMetric {
+				t.Fatalf("expected one imported sample linked to the run, got %v (%v)", samples, err)
+			}
+		}
+	})
+
+	t.Run("import baselines", func(t *testing.T) {
+		target := repo + "-import"
+		run := &types.Run{Repo: target, Branch: "main", CommitHash: "c1", StartedAt: 1, FinishedAt: 2}
+		err := store.CreateRun(run, []types.Benchmark{
+			{Repo: target, Branch: "main", CommitHash: "c1", Component: "parse", Value: 10, Timestamp: 1},
+		})
+		if err != nil {
+			t.Fatalf("create run failed: %v", err)
+		}
+		samples, _ := store.RunBenchmarks(run.ID)
+		baseline := types.Baseline{Repo: target, Component: "parse", Metric: types.DefaultMetric, BaselineValue: 10,
+			SampleCount: 1, UpdatedAt: 2}
+		if err := store.SaveBaseline(baseline, storage.BaselineSource{Source: "rolling", Estimator: "mean"}, samples); err != nil {
+			t.Fatalf("save baseline failed: %v", err)
+		}
+
+		// The imported value replaces the local one; the local samples and
+		// source no longer describe it.
+		baseline.BaselineValue = 20
+		imported, err := store.ImportBaselines([]storage.TransferBaseline{{Baseline: baseline}})
+		if err != nil || imported != 1 {
+			t.Fatalf("expected one imported baseline, got %d (%v)", imported, err)
+		}
+		got, err := store.Baseline(target, "parse", types.DefaultMetric)
+		if err != nil || got.BaselineValue != 20 {
+			t.Fatalf("expected imported baseline 20, got %v (%v)", got, err)
+		}
+		stale, err := store.BaselineSamples(target, "parse", types.DefaultMetric)
+		if err != nil || len(stale) != 0 {
+			t.Fatalf("expected the local baseline samples dropped, got %v (%v)", stale, err)
+		}
+		if _, err := store.BaselineSource(target, "parse", types.DefaultMetric); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected the local baseline source dropped, got %v", err)
+		}
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		target := repo + "-admin"
+		run := &types.Run{Repo: target, Branch: "main", CommitHash: "c1", StartedAt: 1, FinishedAt: 2}
+		err := store.CreateRun(run, []types.Benchmark{
+			{Repo: target, Branch: "main", CommitHash: "c1", Component: "parse", Value: 10, Timestamp: 1},
+			{Repo: target, Branch: "main", CommitHash: "c1", Component: "parse", Value: 12, Timestamp: 1},
+		})
+		if err != nil {
+			t.Fatalf("create run failed: %v", err)
+		}
+		samples, _ := store.RunBenchmarks(run.ID)
+		baseline := types.Baseline{Repo: target, Component: "parse", Metric: types.DefaultMetric, BaselineValue: 11,
+			SampleCount: 2, UpdatedAt: 2}
+		if err := store.SaveBaseline(baseline, storage.BaselineSource{Source: "rolling", Estimator: "mean"}, samples); err != nil {
+			t.Fatalf("save baseline failed: %v", err)
+		}
+		if err := store.SaveAnalysis(run.ID, []storage.AnalysisResult{{Repo: target, Component: "parse"}}); err != nil {
+			t.Fatalf("save analysis failed: %v", err)
+		}
+		if err := store.SaveArtifacts([]types.Artifact{{RunID: run.ID, Repo: target, Name: "log.txt"}}); err != nil {
+			t.Fatalf("save artifacts failed: %v", err)
+		}
+
+		repos, err := store.Repos()
+		found := false
+		for _, r := range repos {
+			if r.Repo == target {
+				found = r.Runs == 1 && r.Series == 1 && r.LastRunAt == 2
+			}
+		}
+		if err != nil || !found {
+			t.Fatalf("expected %s with one run and series, got %v (%v)", target, repos, err)
+		}
+
+		series, err := store.SeriesSummaries(target)
+		if err != nil || len(series) != 1 || series[0].Samples != 2 || series[0].BaselineValue == nil ||
+			series[0].BaselineSource != "rolling" {
+			t.Fatalf("expected one parse series with a rolling baseline, got %v (%v)", series, err)
+		}
+
+		if err := store.DeleteRun(run.ID); err != nil {
+			t.Fatalf("delete run failed: %v", err)
+		}
+		if _, err := store.Run(run.ID); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected deleted run to be gone, got %v", err)
+		}
+		left, _ := store.RunBenchmarks(run.ID)
+		artifacts, _ := store.RunArtifacts(run.ID)
+		analysis, _ := store.RunAnalysis(run.ID)
+		inBaseline, _ := store.BaselineSamples(target, "parse", types.DefaultMetric)
+		if len(left)+len(artifacts)+len(analysis)+len(inBaseline) != 0 {
+			t.Fatalf("expected nothing of the run left, got %d samples, %d artifacts, %d results, %d baseline samples",
+				len(left), len(artifacts), len(analysis), len(inBaseline))
+		}
+		if err := store.DeleteRun(run.ID); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected not found deleting twice, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"regression-ci/pkg/types"
+)
+
+const gobenchOutput = "pkg: example.com/app\nBenchmarkParse-8   1000   1200 ns/op\n"
+
+// ingest posts body to /ingest as go test -bench output for commit c1.
+func (s *testServer) ingest(body []byte, contentType, encoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/ingest?repo=octo/app&branch=main&commit=c1&format=gobench",
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	return s.do(req)
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("zstd failed: %v", err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("zstd failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// hugeWindowFrame is a zstd frame whose header declares a 256MB window for
+// a single raw block holding data, as a hostile client could send.
+func hugeWindowFrame(data []byte) []byte {
+	frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 18 << 3}
+	header := uint32(len(data))<<3 | 1
+	frame = append(frame, byte(header), byte(header>>8), byte(header>>16))
+	return append(frame, data...)
+}
+
+// expandingOutput is valid benchmark output larger than the 1MB test limit.
+func expandingOutput() []byte {
+	return []byte("pkg: example.com/app\n" + strings.Repeat("BenchmarkParse-8   1000   1200 ns/op\n", 60000))
+}
+
+func TestIngestEncodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     func(t *testing.T) []byte
+		encoding string
+		want     int
+	}{
+		{"plain", func(t *testing.T) []byte { return []byte(gobenchOutput) }, "", http.StatusOK},
+		{"gzip", func(t *testing.T) []byte { return gzipped(t, []byte(gobenchOutput)) }, "gzip", http.StatusOK},
+		{"zstd", func(t *testing.T) []byte { return zstdCompressed(t, []byte(gobenchOutput)) }, "zstd", http.StatusOK},
+		{"unsupported encoding", func(t *testing.T) []byte { return []byte(gobenchOutput) }, "br", http.StatusUnsupportedMediaType},
+		{"over the limit as sent", func(t *testing.T) []byte { return expandingOutput() }, "", http.StatusRequestEntityTooLarge},
+		{"gzip over the limit once decoded", func(t *testing.T) []byte { return gzipped(t, expandingOutput()) }, "gzip", http.StatusRequestEntityTooLarge},
+		{"zstd over the limit once decoded", func(t *testing.T) []byte { return zstdCompressed(t, expandingOutput()) }, "zstd", http.StatusRequestEntityTooLarge},
+		{"zstd declaring a huge window", func(t *testing.T) []byte { return hugeWindowFrame([]byte(gobenchOutput)) }, "zstd", http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			rec := s.ingest(tt.body(t), "text/plain", tt.encoding)
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var resp types.AnalyzeResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Components) != 1 {
+				t.Fatalf("expected one analysed component, got %s (%v)", rec.Body, err)
+			}
+		})
+	}
+}
+
+func TestIngestMultipart(t *testing.T) {
+	s := newTestServer(t)
+
+	var profile bytes.Buffer
+	if err := cpuProfile(map[string]int64{"parse": 1000}).Write(&profile); err != nil {
+		t.Fatalf("failed to encode profile: %v", err)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="results"; filename="bench.txt"`},
+		"Content-Type":        {"text/plain"},
+	})
+	part.Write([]byte(gobenchOutput))
+	part, _ = w.CreateFormFile("artifact", "bench.log")
+	part.Write([]byte("log output"))
+	part, _ = w.CreateFormFile("profile[app.Parse]", "cpu.pprof")
+	part.Write(profile.Bytes())
+	w.Close()
+
+	rec := s.ingest(body.Bytes(), w.FormDataContentType(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp types.AnalyzeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Components) != 1 {
+		t.Fatalf("expected one analysed component, got %s (%v)", rec.Body, err)
+	}
+
+	detail := s.do(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/runs/%d", resp.RunID), nil))
+	var run types.RunDetail
+	if err := json.Unmarshal(detail.Body.Bytes(), &run); err != nil {
+		t.Fatalf("failed to read run %d: %s", resp.RunID, detail.Body)
+	}
+	names := map[string]bool{}
+	for _, artifact := range run.Artifacts {
+		names[artifact.Name] = true
+	}
+	if len(names) != 2 || !names["bench.log"] || !names["app.Parse.cpu.pprof"] {
+		t.Fatalf("expected the log and the cpu profile to be kept, got %+v", run.Artifacts)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"regression-ci/internal/regression"
+)
+
+func TestAcknowledgementsCoverTheirHead(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+
+	if resp := s.analyze("head1", 5, 20); !resp.Components[0].Result.IsRegression {
+		t.Fatalf("expected head1 to regress, got %+v", resp.Components[0])
+	}
+
+	if rec := s.comment("maintainer", "/perf accept parse known cost of the new parser"); rec.Code != http.StatusOK {
+		t.Fatalf("expected accept to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+	acks, err := s.store.Acknowledgements("octo/app", 5)
+	if err != nil || len(acks) != 1 || acks[0].HeadSHA != "head1" {
+		t.Fatalf("expected one acknowledgement for head1, got %v (%v)", acks, err)
+	}
+
+	resp := s.analyze("head1", 5, 20)
+	if resp.Components[0].Acknowledgement == nil {
+		t.Fatalf("expected the acknowledgement to cover head1, got %+v", resp.Components[0])
+	}
+
+	resp = s.analyze("head2", 5, 40)
+	if resp.Components[0].Acknowledgement != nil {
+		t.Fatalf("expected a later push not to inherit the acknowledgement, got %+v", resp.Components[0])
+	}
+}
+
+func TestAcceptRejectsUnknownComponents(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+
+	rec := s.comment("maintainer", "/perf accept render not measured here")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the webhook to be acknowledged, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if acks, err := s.store.Acknowledgements("octo/app", 5); err != nil || len(acks) != 0 {
+		t.Fatalf("expected no acknowledgement, got %v (%v)", acks, err)
+	}
+	if !repliedWith(s.github, "`render` is not in the latest analysis") {
+		t.Fatal("expected a reply explaining the rejection")
+	}
+}
+
+func TestCommandPermissionDenialIsAcknowledged(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+
+	rec := s.comment("drive-by", "/perf ignore not my problem")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a 2xx for a denied command, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if acks, err := s.store.Acknowledgements("octo/app", 5); err != nil || len(acks) != 0 {
+		t.Fatalf("expected no acknowledgement, got %v (%v)", acks, err)
+	}
+	if !repliedWith(s.github, "only collaborators with write access") {
+		t.Fatal("expected a reply explaining the denial")
+	}
+}
+
+func repliedWith(github *fakeGitHub, text string) bool {
+	for _, call := range github.Calls(http.MethodPost, "/issues/5/comments") {
+		if strings.Contains(call.Body, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegressionLabelIgnoresAcceptedRegressions(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.analyze("head1", 5, 20)
+
+	if len(s.github.Calls(http.MethodPost, "/issues/5/labels")) != 1 {
+		t.Fatal("expected the open regression to be labelled")
+	}
+
+	s.comment("maintainer", "/perf accept parse known cost of the new parser")
+	if len(s.github.Calls(http.MethodDelete, "/issues/5/labels/"+regression.LabelRegression)) != 1 {
+		t.Fatal("expected the label to be removed once the regression was accepted")
+	}
+}
+
+func TestRegressionLabelSkipsAcceptedPRs(t *testing.T) {
+	s := newTestServer(t)
+	s.seedBaseline()
+	s.label("labeled", regression.LabelAccepted, regression.LabelAccepted)
+	s.analyze("head1", 5, 20)
+
+	if calls := s.github.Calls(http.MethodPost, "/issues/5/labels"); len(calls) != 0 {
+		t.Fatalf("expected no regression label on a perf-accepted PR, got %v", calls)
+	}
+}
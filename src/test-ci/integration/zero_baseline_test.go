@@ -0,0 +1,57 @@
+// Copyright 2025 Baleine Jay
+// Licensed under the Phicode Non-Commercial License (https://banes-lab.com/licensing)
+// Commercial use requires a paid license. See link for details.
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"regression-ci/internal/regression"
+)
+
+func TestNearZeroBaselines(t *testing.T) {
+	// The defaults judge baselines below 5 (10% of 5 is the 0.5 absolute
+	// threshold) by absolute change.
+	tests := []struct {
+		name       string
+		baseline   float64
+		current    float64
+		mode       string
+		regression bool
+	}{
+		{"zero within the absolute threshold", 0, 0.4, regression.ThresholdModeAbsolute, false},
+		{"zero beyond the absolute threshold", 0, 1, regression.ThresholdModeAbsolute, true},
+		{"tiny baseline ignores a large ratio", 0.01, 0.05, regression.ThresholdModeAbsolute, false},
+		{"tiny baseline beyond the absolute threshold", 0.01, 2, regression.ThresholdModeAbsolute, true},
+		{"large baseline within the relative threshold", 100, 105, regression.ThresholdModeRelative, false},
+		{"large baseline beyond the relative threshold", 100, 120, regression.ThresholdModeRelative, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, detector := newDetector(t)
+			repo := "zero/repo"
+			for i := 0; i < 6; i++ {
+				analyze(t, detector, repo, fmt.Sprintf("base%d", i), tt.baseline)
+			}
+
+			response := analyze(t, detector, repo, "head", tt.current)
+			result := response.Components[0].Result
+			if result == nil {
+				t.Fatalf("expected a result, got %+v", response.Components[0])
+			}
+			if result.ThresholdMode != tt.mode || result.IsRegression != tt.regression {
+				t.Fatalf("expected %s mode and regression %v, got %s and %v", tt.mode, tt.regression, result.ThresholdMode, result.IsRegression)
+			}
+			if math.IsNaN(result.PercentChange) || math.IsInf(result.PercentChange, 0) {
+				t.Fatalf("expected a finite percent change, got %v", result.PercentChange)
+			}
+			if _, err := json.Marshal(response); err != nil {
+				t.Fatalf("expected the response to encode: %v", err)
+			}
+		})
+	}
+}
@@ -60,7 +60,8 @@ REM Start server in background for integration tests
 echo.
 echo [6/8] Starting server for integration tests...
 set WEBHOOK_SECRET=test-secret-key
-set DATABASE_PATH=:memory:
+set REGRESSION_CI_GITHUB_WEBHOOK_SECRET=%WEBHOOK_SECRET%
+set REGRESSION_CI_DATABASE_PATH=:memory:
 start /B bin\regression-server.exe > server.log 2>&1
 set SERVER_PID=!ERRORLEVEL!
 